@@ -20,9 +20,38 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/tasks": {
+        "/admin/pool": {
+            "get": {
+                "description": "This api for reading the queue length, busy and idle workers and the throughput of the pool",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get Pool Stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PoolStats"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/pool/drain": {
             "post": {
-                "description": "This api for create task",
+                "description": "This api for refusing new tasks and waiting until the queued and running tasks are done, it answers 202 when the pool is still draining at the timeout",
                 "consumes": [
                     "application/json"
                 ],
@@ -30,9 +59,122 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Admin"
                 ],
-                "summary": "Create Task",
+                "summary": "Drain Pool",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "30s",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PoolStats"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PoolStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/pool/pause": {
+            "post": {
+                "description": "This api for stopping the dispatch of queued tasks, running tasks finish and new tasks are still accepted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Pause Pool",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PoolStats"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/pool/resume": {
+            "post": {
+                "description": "This api for restarting the dispatch of a paused pool, a drained pool accepts tasks again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Resume Pool",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PoolStats"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/pool/workers": {
+            "put": {
+                "description": "This api for changing the worker count of the pool without a restart, removed workers finish their current task first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Set Pool Workers",
                 "parameters": [
                     {
                         "description": "Contains information to set data",
@@ -40,15 +182,15 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateTaskRequest"
+                            "$ref": "#/definitions/dto.SetWorkersRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Task"
+                            "$ref": "#/definitions/dto.PoolStats"
                         }
                     },
                     "400": {
@@ -72,9 +214,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/purge/{id}": {
-            "delete": {
-                "description": "This api for purge task",
+        "/schedules": {
+            "get": {
+                "description": "This api for list schedules with filters, sorting and pagination",
                 "consumes": [
                     "application/json"
                 ],
@@ -82,21 +224,130 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Schedule"
                 ],
-                "summary": "Purge Task",
+                "summary": "Get Schedules",
                 "parameters": [
                     {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "name": "names",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 12,
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "paused",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "createdAt",
+                            "updatedAt",
+                            "name",
+                            "nextRunAt"
+                        ],
                         "type": "string",
-                        "description": "Task Id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "default": "DESC",
+                        "name": "sortType",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.ListResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.Schedule"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This api for create a recurring schedule which fires a task on every run of its cron expression",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Create Schedule",
+                "parameters": [
+                    {
+                        "description": "Contains information to set data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Schedule"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -119,9 +370,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}": {
+        "/schedules/{id}": {
             "get": {
-                "description": "This api for task by id",
+                "description": "This api for schedule by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -129,13 +380,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Schedule"
                 ],
-                "summary": "Get Task By Id",
+                "summary": "Get Schedule By Id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Task Id",
+                        "description": "Schedule Id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -145,7 +396,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Task"
+                            "$ref": "#/definitions/dto.Schedule"
                         }
                     },
                     "400": {
@@ -154,10 +405,10 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrSwaggerResponse"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
                         }
                     },
                     "500": {
@@ -169,7 +420,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "This api for update task",
+                "description": "This api for update the definition of a schedule, its next run is planned again",
                 "consumes": [
                     "application/json"
                 ],
@@ -177,13 +428,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Schedule"
                 ],
-                "summary": "Update Task",
+                "summary": "Update Schedule",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Task Id",
+                        "description": "Schedule Id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -194,7 +445,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateTaskRequest"
+                            "$ref": "#/definitions/dto.UpdateScheduleRequest"
                         }
                     }
                 ],
@@ -202,7 +453,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Task"
+                            "$ref": "#/definitions/dto.Schedule"
                         }
                     },
                     "400": {
@@ -211,6 +462,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrSwaggerResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -226,7 +483,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "This api for delete task",
+                "description": "This api for delete schedule, the tasks it already created are kept",
                 "consumes": [
                     "application/json"
                 ],
@@ -234,13 +491,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Schedule"
                 ],
-                "summary": "Delete Task",
+                "summary": "Delete Schedule",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Task Id",
+                        "description": "Schedule Id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -256,12 +513,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrSwaggerResponse"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -270,47 +521,1567 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.CreateTaskRequest": {
-            "type": "object",
-            "required": [
-                "description",
-                "title"
-            ],
-            "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
-                }
-            }
         },
-        "dto.Task": {
-            "type": "object",
-            "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
+        "/schedules/{id}/pause": {
+            "post": {
+                "description": "This api for pause a schedule, the runs which fall in the pause are not fired",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Pause Schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/resume": {
+            "post": {
+                "description": "This api for resume a paused schedule from its next run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Resume Schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "description": "This api for list tasks with filters, sorting and pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get Tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1s",
+                        "name": "durationFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "5s",
+                        "name": "durationTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "name": "ids",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 12,
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "createdAt",
+                            "updatedAt",
+                            "title",
+                            "status",
+                            "duration",
+                            "priority",
+                            "runAt",
+                            "queuedAt",
+                            "startedAt",
+                            "finishedAt"
+                        ],
+                        "type": "string",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "default": "DESC",
+                        "name": "sortType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "name": "statuses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "name": "titles",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "updatedTo",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.ListResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.Task"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This api for create task, a request sent again with the same Idempotency-Key gets the response of the first one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Create Task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request, up to 255 characters",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Contains information to set data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/dead-letters": {
+            "get": {
+                "description": "This api for list tasks which used up all of their attempts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get Dead Letter Tasks",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 12,
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "default": "DESC",
+                        "name": "sortType",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.ListResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.Task"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/purge/{id}": {
+            "delete": {
+                "description": "This api for purge task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Purge Task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/stream": {
+            "get": {
+                "description": "This api for a server-sent event stream of the status changes of every task, optionally only the ones to the given statuses. A client which reconnects with Last-Event-ID gets the stored events it missed first",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Stream Events Of All Tasks",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "name": "statuses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskStreamEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/subscribe": {
+            "get": {
+                "description": "This api for a WebSocket which pushes the status changes of the subscribed tasks. The client sends dto.SubscriptionRequest messages to subscribe to or unsubscribe from task ids and statuses and gets the whole subscription back, then every matching event as a dto.SubscriptionReply. The server pings every 54s and drops a connection which does not answer within 60s or falls 64 messages behind.",
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Subscribe To Task Events",
+                "parameters": [
+                    {
+                        "description": "Message sent over the socket",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionReply"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "get": {
+                "description": "This api for task by id, the result of the task is returned once it is completed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get Task By Id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This api for update task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Update Task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Contains information to set data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This api for delete task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Delete Task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/cancel": {
+            "post": {
+                "description": "This api for cancel a pending or running task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Cancel Task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/events": {
+            "get": {
+                "description": "This api for the ordered lifecycle events of a task with the time elapsed between them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get Task Timeline",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskTimeline"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/redrive": {
+            "post": {
+                "description": "This api for moving a dead lettered task back to the pool with a fresh attempt budget",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Re-drive Dead Letter Task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/stream": {
+            "get": {
+                "description": "This api for a server-sent event stream of the status changes of a task. The stream starts with the recorded events of the task, or with the ones after Last-Event-ID, and ends once the task settles in a final status, the dead letter state or a timeout which is not retried",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Stream Task Events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskStreamEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/wait": {
+            "get": {
+                "description": "This api for waiting until a task settles in a final, dead letter or not retried timed out status, the current state of the task is returned once it does or once the timeout expires",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Wait For Task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "30s",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrValidationSwaggerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrSwaggerResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.CreateScheduleRequest": {
+            "type": "object",
+            "required": [
+                "expression",
+                "name",
+                "task"
+            ],
+            "properties": {
+                "catchUp": {
+                    "type": "string",
+                    "enum": [
+                        "SKIP",
+                        "ONCE",
+                        "ALL"
+                    ],
+                    "example": "ONCE"
+                },
+                "expression": {
+                    "type": "string",
+                    "example": "*/5 * * * *"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "noOverlap": {
+                    "type": "boolean"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "task": {
+                    "$ref": "#/definitions/dto.TaskTemplate"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "UTC"
+                }
+            }
+        },
+        "dto.CreateTaskRequest": {
+            "type": "object",
+            "required": [
+                "description",
+                "title"
+            ],
+            "properties": {
+                "delay": {
+                    "type": "string",
+                    "example": "10m"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 9,
+                    "minimum": 0,
+                    "example": 5
+                },
+                "retry": {
+                    "$ref": "#/definitions/dto.RetryPolicy"
+                },
+                "runAt": {
+                    "description": "RunAt and Delay postpone the task, a time in the past runs it right away",
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "string",
+                    "example": "30s"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "sleep"
+                }
+            }
+        },
+        "dto.PoolStats": {
+            "type": "object",
+            "properties": {
+                "busyWorkers": {
+                    "type": "integer"
+                },
+                "draining": {
+                    "type": "boolean"
+                },
+                "idleWorkers": {
+                    "type": "integer"
+                },
+                "maxWorkers": {
+                    "type": "integer"
+                },
+                "minWorkers": {
+                    "type": "integer"
+                },
+                "panics": {
+                    "type": "integer"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "queueCapacity": {
+                    "type": "integer"
+                },
+                "queueLength": {
+                    "type": "integer"
+                },
+                "retrying": {
+                    "type": "integer"
+                },
+                "targetWorkers": {
+                    "type": "integer"
+                },
+                "throughput": {
+                    "type": "number",
+                    "example": 2.5
+                },
+                "workers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.RetryPolicy": {
+            "type": "object",
+            "properties": {
+                "backoff": {
+                    "type": "string",
+                    "enum": [
+                        "FIXED",
+                        "EXPONENTIAL"
+                    ],
+                    "example": "EXPONENTIAL"
+                },
+                "delay": {
+                    "type": "string",
+                    "example": "1s"
+                },
+                "jitter": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.2
+                },
+                "maxAttempts": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "maxDelay": {
+                    "type": "string",
+                    "example": "1m"
+                }
+            }
+        },
+        "dto.Schedule": {
+            "type": "object",
+            "properties": {
+                "catchUp": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "expression": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastRunAt": {
+                    "type": "string"
+                },
+                "lastTaskId": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nextRunAt": {
+                    "type": "string"
+                },
+                "noOverlap": {
+                    "type": "boolean"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "task": {
+                    "$ref": "#/definitions/dto.Template"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SetWorkersRequest": {
+            "type": "object",
+            "required": [
+                "workers"
+            ],
+            "properties": {
+                "workers": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 8
+                }
+            }
+        },
+        "dto.SubscriptionReply": {
+            "type": "object",
+            "properties": {
+                "causes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.ResponseValidation"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/dto.TaskStreamEvent"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "statuses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "subscribed",
+                        "event",
+                        "error"
+                    ]
+                }
+            }
+        },
+        "dto.SubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "subscribe",
+                        "unsubscribe"
+                    ]
+                },
+                "ids": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "statuses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.Task": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
                 "duration": {
                     "type": "integer"
                 },
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
+                "lastError": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "queuedAt": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "retry": {
+                    "$ref": "#/definitions/dto.RetryPolicy"
+                },
+                "runAt": {
+                    "type": "string"
+                },
+                "runTime": {
+                    "type": "string",
+                    "example": "3.2s"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
                 "status": {
                     "type": "string"
                 },
+                "timeout": {
+                    "type": "string"
+                },
                 "title": {
                     "type": "string"
                 },
+                "type": {
+                    "type": "string"
+                },
                 "updatedAt": {
                     "type": "string"
+                },
+                "waitTime": {
+                    "type": "string",
+                    "example": "250ms"
+                },
+                "workerId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TaskEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "elapsed": {
+                    "type": "string",
+                    "example": "1.5s"
+                },
+                "fromStatus": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "SCHEDULED",
+                        "QUEUED",
+                        "STARTED",
+                        "ATTEMPT_FAILED",
+                        "TIMED_OUT",
+                        "RETRIED",
+                        "REDRIVEN",
+                        "COMPLETED",
+                        "FAILED",
+                        "CANCELLED",
+                        "DEAD_LETTERED"
+                    ]
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "toStatus": {
+                    "type": "string"
+                },
+                "workerId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TaskStreamEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "fromStatus": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "SCHEDULED",
+                        "QUEUED",
+                        "STARTED",
+                        "ATTEMPT_FAILED",
+                        "TIMED_OUT",
+                        "RETRIED",
+                        "REDRIVEN",
+                        "COMPLETED",
+                        "FAILED",
+                        "CANCELLED",
+                        "DEAD_LETTERED"
+                    ]
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "taskId": {
+                    "type": "string"
+                },
+                "toStatus": {
+                    "type": "string"
+                },
+                "workerId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TaskTemplate": {
+            "type": "object",
+            "required": [
+                "description",
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 9,
+                    "minimum": 0,
+                    "example": 5
+                },
+                "retry": {
+                    "$ref": "#/definitions/dto.RetryPolicy"
+                },
+                "timeout": {
+                    "type": "string",
+                    "example": "30s"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "sleep"
+                }
+            }
+        },
+        "dto.TaskTimeline": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TaskEvent"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "taskId": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total is the time between the first and the last event",
+                    "type": "string",
+                    "example": "3.2s"
+                }
+            }
+        },
+        "dto.Template": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "retry": {
+                    "$ref": "#/definitions/dto.RetryPolicy"
+                },
+                "timeout": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UpdateScheduleRequest": {
+            "type": "object",
+            "required": [
+                "expression",
+                "name",
+                "task"
+            ],
+            "properties": {
+                "catchUp": {
+                    "type": "string",
+                    "enum": [
+                        "SKIP",
+                        "ONCE",
+                        "ALL"
+                    ],
+                    "example": "ONCE"
+                },
+                "expression": {
+                    "type": "string",
+                    "example": "*/5 * * * *"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "noOverlap": {
+                    "type": "boolean"
+                },
+                "task": {
+                    "$ref": "#/definitions/dto.TaskTemplate"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "UTC"
                 }
             }
         },
@@ -329,6 +2100,17 @@ const docTemplate = `{
                 }
             }
         },
+        "response.DefaultSort": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
         "response.ErrSwaggerResponse": {
             "type": "object",
             "properties": {
@@ -386,6 +2168,43 @@ const docTemplate = `{
                     "additionalProperties": true
                 }
             }
+        },
+        "response.ListResponse": {
+            "type": "object",
+            "properties": {
+                "defaultSort": {
+                    "$ref": "#/definitions/response.DefaultSort"
+                },
+                "items": {},
+                "pagination": {
+                    "$ref": "#/definitions/response.PaginationInfo"
+                }
+            }
+        },
+        "response.PaginationInfo": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer"
+                },
+                "pageSize": {
+                    "type": "integer"
+                },
+                "totalItems": {
+                    "type": "integer"
+                }
+            }
+        },
+        "validation.ResponseValidation": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -1,56 +1,22 @@
 package main
 
 import (
-	"context"
 	logger "log"
-	"os"
-	"os/signal"
-	"sync/atomic"
-	"syscall"
-	"time"
 
 	"github.com/thealiakbari/task-pool-system/cmd"
-	"golang.org/x/sync/errgroup"
 )
 
 func main() {
 	conf := cmd.Setup()
+	defer conf.Cancel()
 
-	ctx, cancel := context.WithCancel(conf.Ctx)
-	defer cancel()
-
-	var healthy int32 = 1
-	errGroup, ctx := errgroup.WithContext(ctx)
-
-	// NOTE: Run the http and gRPC Server
-	server := httpServer(conf)
-	// Handle OS signals for graceful shutdown
-	errGroup.Go(func() error {
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		select {
-		case sig := <-sigCh:
-			logger.Printf("Received signal: %v, shutting down...", sig)
-			atomic.StoreInt32(&healthy, 0)
-
-			shutdownCtx, cancel := context.WithTimeout(conf.Ctx, 5*time.Second)
-			defer cancel()
-			if err := server.Shutdown(shutdownCtx); err != nil {
-				return err
-			}
-			cancel()
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		}
-	})
-
-	// Wait for all goroutines to finish
-	if err := errGroup.Wait(); err != nil && atomic.LoadInt32(&healthy) == 1 {
+	// NOTE: Run the http Server, the lifecycle stops it along with the pool and the database on SIGINT or SIGTERM
+	lifecycle := cmd.NewLifecycle(conf, httpServer(conf))
+	if err := lifecycle.Run(); err != nil {
 		logger.Fatalf("Error occurred: %v", err)
-	} else {
-		conf.Logger.Info(nil, "Shutdown complete.")
 	}
+
+	conf.Logger.Info(nil, "Shutdown complete.")
 }
 
 // @termsOfService  http://swagger.io/terms/
@@ -65,11 +31,14 @@ func httpServer(conf *cmd.SetupConfig) *Server {
 	server := NewServer(
 		conf.Conf,
 		conf.HttpAdaptorStorage.TaskAdaptor,
+		conf.HttpAdaptorStorage.SubscriptionAdaptor,
+		conf.HttpAdaptorStorage.ScheduleAdaptor,
+		conf.HttpAdaptorStorage.AdminAdaptor,
 	)
 
-	server.HealthCheck()
+	server.HealthCheck(conf.Health)
+	server.Metrics()
 	server.SwaggerApi()
-	go server.Start()
 
 	return server
 }
@@ -2,16 +2,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/thealiakbari/task-pool-system/cmd/executor/docs"
 	"github.com/thealiakbari/task-pool-system/pkg/common/config"
 	"github.com/thealiakbari/task-pool-system/pkg/common/ginh"
+	"github.com/thealiakbari/task-pool-system/pkg/common/health"
+	"github.com/thealiakbari/task-pool-system/pkg/common/metrics"
 	"github.com/thealiakbari/task-pool-system/pkg/common/response"
 )
 
@@ -21,15 +25,21 @@ type Handler interface {
 
 type Server struct {
 	router *gin.Engine
+	srv    *http.Server
 	conf   *config.AppConfig
 }
 
 func NewServer(conf *config.AppConfig, handlers ...Handler) *Server {
 	r := ginh.NewGinEngine(conf.Mode)
+	r.Use(metrics.NewHTTPMetrics(prometheus.DefaultRegisterer).Middleware())
 
 	server := &Server{
 		router: r,
-		conf:   conf,
+		srv: &http.Server{
+			Addr:    conf.Core.Http.Address,
+			Handler: r,
+		},
+		conf: conf,
 	}
 
 	server.registerRoutes(handlers...)
@@ -43,11 +53,14 @@ func (s *Server) registerRoutes(handlers ...Handler) {
 	}
 }
 
-func (s *Server) Start() {
-	err := s.router.Run(s.conf.Core.Http.Address)
-	if err != nil {
-		panic(err)
+// Start serves until Shutdown is called
+func (s *Server) Start() error {
+	log.Printf("HTTP server listening on %s", s.srv.Addr)
+	if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+
+	return nil
 }
 
 func (s *Server) SwaggerApi() {
@@ -64,19 +77,37 @@ func (s *Server) SwaggerApi() {
 	}
 }
 
-func (s *Server) HealthCheck() {
+// HealthCheck serves the liveness and the readiness of the executor with the state of every component,
+// both answer 503 once a check of theirs fails
+func (s *Server) HealthCheck(checks *health.Registry) {
 	s.router.GET("/ping", func(ctx *gin.Context) {
 		response.OKResponse(ctx, map[string]string{"message": "pong"})
 	})
+	s.router.GET("/healthz/live", func(ctx *gin.Context) {
+		healthResponse(ctx, checks.Live(ctx.Request.Context()))
+	})
+	s.router.GET("/healthz/ready", func(ctx *gin.Context) {
+		healthResponse(ctx, checks.Ready(ctx.Request.Context()))
+	})
 }
 
-func (s *Server) Shutdown(ctx context.Context) error {
-	srv := &http.Server{
-		Addr:    s.conf.Core.Http.Address,
-		Handler: s.router,
+func healthResponse(ctx *gin.Context, report health.Report) {
+	status := http.StatusOK
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
 	}
 
-	if err := srv.Shutdown(ctx); err != nil {
+	response.StatusResponse(ctx, status, report)
+}
+
+// Metrics serves the Prometheus metrics of the API, the pool and the database
+func (s *Server) Metrics() {
+	s.router.GET("/metrics", gin.WrapH(metrics.Handler()))
+}
+
+// Shutdown stops accepting connections and waits for the in-flight requests until ctx ends
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.srv.Shutdown(ctx); err != nil {
 		return err
 	}
 	log.Println("HTTP server shut down gracefully.")
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+	"github.com/thealiakbari/task-pool-system/pkg/common/health"
+)
+
+// HttpServer is the part of the executor's server the lifecycle drives, Start blocks until the server stops
+type HttpServer interface {
+	Start() error
+	Shutdown(ctx context.Context) error
+}
+
+// Workers are the background components which run the tasks, Stop gives the running tasks until ctx ends
+type Workers interface {
+	Start()
+	Stop(ctx context.Context) error
+}
+
+// Lifecycle owns the HTTP server, the workers and the database of the executor and stops them in order:
+// the server stops taking requests first, the workers get the rest of the grace period to finish their
+// tasks and the database is closed last, once nothing writes to it anymore. The executor reports not ready
+// from the start until the migrations are done and the workers run, and again from the start of the shutdown.
+type Lifecycle struct {
+	setup    *SetupConfig
+	server   HttpServer
+	workers  Workers
+	database io.Closer
+	gate     *health.Gate
+}
+
+func NewLifecycle(setup *SetupConfig, server HttpServer) *Lifecycle {
+	gate := health.NewGate("starting")
+	setup.Health.AddReadiness("lifecycle", gate.Check)
+
+	return &Lifecycle{
+		setup:    setup,
+		server:   server,
+		workers:  setup.Workers,
+		database: setup.DB,
+		gate:     gate,
+	}
+}
+
+// Run serves until SIGINT or SIGTERM arrives or the server fails, then shuts everything down. The server
+// starts before the migrations run so the health endpoints answer while they do.
+func (l *Lifecycle) Run() error {
+	ctx, stop := signal.NotifyContext(l.setup.Ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- l.server.Start()
+	}()
+
+	if err := l.start(); err != nil {
+		return errors.Join(err, l.Shutdown())
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Println("[LIFECYCLE] shutdown requested")
+	case err = <-served:
+		log.Printf("[LIFECYCLE] http server stopped: %v", err)
+	}
+
+	return errors.Join(err, l.Shutdown())
+}
+
+// start migrates the database and starts the workers, the executor is ready once both are done
+func (l *Lifecycle) start() error {
+	l.gate.Close("running migrations")
+	logInfra := l.setup.Logger.CloneAsInfra()
+	if err := db.Migrate(l.setup.Conf.DB.Postgres, logInfra); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	logInfra.Info("Migrations successfully done.")
+
+	l.workers.Start()
+	l.gate.Open()
+	log.Println("[LIFECYCLE] ready")
+
+	return nil
+}
+
+// Shutdown stops every component within the shutdown grace of the pool. The executor keeps serving for the
+// drain delay once it reports not ready, so the load balancers which poll the readiness stop sending it
+// requests before the server refuses them.
+func (l *Lifecycle) Shutdown() error {
+	l.gate.Close("shutting down")
+	if delay := l.setup.Conf.Pool.DrainDelay; delay > 0 {
+		log.Printf("[LIFECYCLE] serving for %s before the shutdown", delay)
+		time.Sleep(delay)
+	}
+
+	// The event streams and the waits for a task end with the bus and the notifier, they are closed so the
+	// server does not wait for them
+	l.setup.Events.Close()
+	l.setup.Completions.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.setup.Conf.Pool.ShutdownGrace)
+	defer cancel()
+
+	var errs []error
+	if err := l.server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http server: %w", err))
+	}
+
+	if err := l.workers.Stop(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("workers: %w", err))
+	}
+
+	l.setup.Cancel()
+
+	if err := l.database.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("database: %w", err))
+	}
+
+	log.Println("[LIFECYCLE] shutdown completed")
+	return errors.Join(errs...)
+}
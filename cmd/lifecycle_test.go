@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/bus"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/notifier"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/pool"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+	"github.com/thealiakbari/task-pool-system/pkg/common/config"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+	"github.com/thealiakbari/task-pool-system/pkg/common/health"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+)
+
+// calls records the order the components are stopped in
+type calls struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (c *calls) add(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.names = append(c.names, name)
+}
+
+func (c *calls) list() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.names...)
+}
+
+// fakeServer checks the executor reports not ready by the time it is shut down
+type fakeServer struct {
+	calls  *calls
+	health *health.Registry
+	ready  health.Status
+}
+
+func (f *fakeServer) Start() error {
+	return nil
+}
+
+func (f *fakeServer) Shutdown(ctx context.Context) error {
+	f.ready = f.health.Ready(ctx).Status
+	f.calls.add("server")
+	return nil
+}
+
+type fakeFeeder struct {
+	name  string
+	calls *calls
+}
+
+func (f fakeFeeder) Shutdown() {
+	f.calls.add(f.name)
+}
+
+type fakePool struct {
+	calls *calls
+}
+
+func (f fakePool) Stop(ctx context.Context) error {
+	f.calls.add("pool")
+	return nil
+}
+
+// fakeWorkers stops the pool the way WorkerStorage does, behind a runner and a scheduler which record their shutdown
+type fakeWorkers struct {
+	calls *calls
+	pool  interface{ Stop(context.Context) error }
+}
+
+func (f fakeWorkers) Start() {}
+
+func (f fakeWorkers) Stop(ctx context.Context) error {
+	return stopWorkers(ctx, f.pool, fakeFeeder{name: "runner", calls: f.calls}, fakeFeeder{name: "scheduler", calls: f.calls})
+}
+
+type fakeDatabase struct {
+	calls *calls
+}
+
+func (f fakeDatabase) Close() error {
+	f.calls.add("database")
+	return nil
+}
+
+func newTestLifecycle(t *testing.T, poolConf config.Pool, workers Workers, order *calls) (*Lifecycle, *fakeServer) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	setup := &SetupConfig{
+		Ctx:         ctx,
+		Cancel:      cancel,
+		Conf:        &config.AppConfig{Pool: poolConf},
+		Health:      health.NewRegistry(),
+		Events:      bus.New(1),
+		Completions: notifier.New(ctx, 0),
+		Workers:     workers,
+	}
+	server := &fakeServer{calls: order, health: setup.Health}
+
+	lifecycle := NewLifecycle(setup, server)
+	lifecycle.database = fakeDatabase{calls: order}
+	return lifecycle, server
+}
+
+func TestShutdown_StopsComponentsInOrder(t *testing.T) {
+	order := &calls{}
+	lifecycle, server := newTestLifecycle(t, config.Pool{ShutdownGrace: time.Second}, fakeWorkers{calls: order, pool: fakePool{calls: order}}, order)
+	lifecycle.gate.Open()
+
+	assert.NoError(t, lifecycle.Shutdown())
+	assert.Equal(t, []string{"server", "runner", "scheduler", "pool", "database"}, order.list())
+	assert.Equal(t, health.StatusDown, server.ready, "the executor must report not ready before the server stops")
+}
+
+func TestShutdown_ServesForDrainDelay(t *testing.T) {
+	order := &calls{}
+	poolConf := config.Pool{ShutdownGrace: time.Second, DrainDelay: 100 * time.Millisecond}
+	lifecycle, _ := newTestLifecycle(t, poolConf, fakeWorkers{calls: order, pool: fakePool{calls: order}}, order)
+	lifecycle.gate.Open()
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- lifecycle.Shutdown()
+	}()
+
+	assert.Eventually(t, func() bool {
+		return lifecycle.setup.Health.Ready(context.Background()).Status == health.StatusDown
+	}, time.Second, 5*time.Millisecond)
+	assert.Empty(t, order.list(), "the server must keep serving for the drain delay")
+
+	select {
+	case err := <-shutdown:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "the shutdown did not end")
+	}
+	assert.Equal(t, []string{"server", "runner", "scheduler", "pool", "database"}, order.list())
+}
+
+// fakeTaskService keeps the transitions the pool saves, the durable backlog is always empty
+type fakeTaskService struct {
+	task.TaskService
+
+	mu      sync.Mutex
+	updates map[uuid.UUID]entity.Task
+}
+
+func (f *fakeTaskService) ClaimPending(ctx context.Context, owner string, limit int, ttl time.Duration) ([]entity.Task, error) {
+	return nil, nil
+}
+
+func (f *fakeTaskService) Lease(ctx context.Context, id uuid.UUID, owner string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeTaskService) RenewLeases(ctx context.Context, owner string, ids []uuid.UUID, ttl time.Duration) error {
+	return nil
+}
+
+func (f *fakeTaskService) List(ctx context.Context, filter entity.TaskFilter, portion request.Portion) ([]entity.Task, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeTaskService) ReleaseLeases(ctx context.Context, owner string) error {
+	return nil
+}
+
+func (f *fakeTaskService) ReclaimExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeTaskService) Transition(ctx context.Context, in entity.Task, transition entity.Transition) (entity.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates[in.Id] = in
+	return in, nil
+}
+
+func (f *fakeTaskService) statusOf(id uuid.UUID) entity.Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.updates[id].Status
+}
+
+func TestShutdown_RequeuesTasksRunningAfterGrace(t *testing.T) {
+	svc := &fakeTaskService{updates: make(map[uuid.UUID]entity.Task)}
+	handlers := handler.NewRegistry()
+	handlers.MustRegister("block", handler.HandlerFunc(func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+
+	workerPool := pool.New(context.Background(), 1, 1)
+	workerPool.Start(pool.WorkerDeps{TaskService: svc, Handlers: handlers})
+
+	item := &entity.Task{
+		UniversalModel: db.UniversalModel{Id: uuid.New()},
+		Status:         entity.StatusPending,
+		Type:           "block",
+		Retry:          entity.RetryPolicy{MaxAttempts: 1},
+	}
+	require.NoError(t, workerPool.Submit(item))
+	require.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusRunning
+	}, time.Second, 5*time.Millisecond)
+
+	order := &calls{}
+	lifecycle, _ := newTestLifecycle(t, config.Pool{ShutdownGrace: 50 * time.Millisecond}, fakeWorkers{calls: order, pool: workerPool}, order)
+
+	assert.ErrorIs(t, lifecycle.Shutdown(), context.DeadlineExceeded)
+	assert.Equal(t, entity.StatusPending, svc.statusOf(item.Id), "a task interrupted by the shutdown is requeued, not failed")
+	assert.Equal(t, []string{"server", "runner", "scheduler", "database"}, order.list())
+}
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_tasks_status;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS max_attempts,
+    DROP COLUMN IF EXISTS backoff,
+    DROP COLUMN IF EXISTS backoff_delay,
+    DROP COLUMN IF EXISTS backoff_max_delay,
+    DROP COLUMN IF EXISTS backoff_jitter,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS last_error;
@@ -0,0 +1,10 @@
+ALTER TABLE tasks
+    ADD COLUMN max_attempts      int              NOT NULL DEFAULT 1,
+    ADD COLUMN backoff           varchar(32)      NOT NULL DEFAULT 'FIXED',
+    ADD COLUMN backoff_delay     bigint           NOT NULL DEFAULT 0,
+    ADD COLUMN backoff_max_delay bigint           NOT NULL DEFAULT 0,
+    ADD COLUMN backoff_jitter    double precision NOT NULL DEFAULT 0,
+    ADD COLUMN attempts          int              NOT NULL DEFAULT 0,
+    ADD COLUMN last_error        text             NOT NULL DEFAULT '';
+
+CREATE INDEX idx_tasks_status ON tasks (status);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_status_lease;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS lease_owner,
+    DROP COLUMN IF EXISTS lease_expires_at;
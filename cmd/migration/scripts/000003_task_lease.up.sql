@@ -0,0 +1,5 @@
+ALTER TABLE tasks
+    ADD COLUMN lease_owner      varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN lease_expires_at timestamptz;
+
+CREATE INDEX idx_tasks_status_lease ON tasks (status, lease_expires_at) WHERE deleted_at IS NULL;
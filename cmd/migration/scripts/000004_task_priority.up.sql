@@ -0,0 +1,2 @@
+ALTER TABLE tasks
+    ADD COLUMN priority int NOT NULL DEFAULT 5;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tasks_scheduled_run_at;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS run_at;
@@ -0,0 +1,4 @@
+ALTER TABLE tasks
+    ADD COLUMN run_at timestamptz;
+
+CREATE INDEX idx_tasks_scheduled_run_at ON tasks (run_at) WHERE status = 'SCHEDULED' AND deleted_at IS NULL;
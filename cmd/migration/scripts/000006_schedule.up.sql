@@ -0,0 +1,28 @@
+CREATE TABLE schedules
+(
+    id                     uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at             timestamptz NOT NULL DEFAULT now(),
+    updated_at             timestamptz NOT NULL DEFAULT now(),
+    deleted_at             timestamptz,
+
+    name                   varchar(255)     NOT NULL,
+    expression             varchar(255)     NOT NULL,
+    timezone               varchar(64)      NOT NULL DEFAULT 'UTC',
+    catch_up               varchar(16)      NOT NULL DEFAULT 'ONCE',
+    no_overlap             boolean          NOT NULL DEFAULT false,
+    paused                 boolean          NOT NULL DEFAULT false,
+    next_run_at            timestamptz,
+    last_run_at            timestamptz,
+    last_task_id           uuid,
+
+    task_title             varchar(255)     NOT NULL,
+    task_description       text             NOT NULL,
+    task_priority          int              NOT NULL DEFAULT 5,
+    task_max_attempts      int              NOT NULL DEFAULT 1,
+    task_backoff           varchar(32)      NOT NULL DEFAULT 'FIXED',
+    task_backoff_delay     bigint           NOT NULL DEFAULT 0,
+    task_backoff_max_delay bigint           NOT NULL DEFAULT 0,
+    task_backoff_jitter    double precision NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_schedules_next_run_at ON schedules (next_run_at) WHERE paused = false AND deleted_at IS NULL;
@@ -0,0 +1,9 @@
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS task_type,
+    DROP COLUMN IF EXISTS task_payload;
+
+DROP INDEX IF EXISTS idx_tasks_type;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS type,
+    DROP COLUMN IF EXISTS payload;
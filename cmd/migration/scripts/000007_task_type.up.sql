@@ -0,0 +1,14 @@
+ALTER TABLE tasks
+    ADD COLUMN type    varchar(64) NOT NULL DEFAULT 'sleep',
+    ADD COLUMN payload jsonb;
+
+-- The tasks created before the handlers existed only slept for their duration
+UPDATE tasks
+SET payload = jsonb_build_object('duration', (duration / 1000)::text || 'us')
+WHERE payload IS NULL;
+
+CREATE INDEX idx_tasks_type ON tasks (type);
+
+ALTER TABLE schedules
+    ADD COLUMN task_type    varchar(64) NOT NULL DEFAULT 'sleep',
+    ADD COLUMN task_payload jsonb;
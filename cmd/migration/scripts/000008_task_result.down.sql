@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS result,
+    DROP COLUMN IF EXISTS error;
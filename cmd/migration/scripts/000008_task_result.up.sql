@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+    ADD COLUMN result jsonb,
+    ADD COLUMN error  text NOT NULL DEFAULT '';
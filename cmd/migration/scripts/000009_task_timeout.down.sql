@@ -0,0 +1,5 @@
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS task_timeout;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS timeout;
@@ -0,0 +1,5 @@
+ALTER TABLE tasks
+    ADD COLUMN timeout bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE schedules
+    ADD COLUMN task_timeout bigint NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+CREATE TABLE task_events
+(
+    id          uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at  timestamptz NOT NULL DEFAULT now(),
+
+    task_id     uuid NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    from_status varchar(32) NOT NULL,
+    to_status   varchar(32) NOT NULL,
+    worker_id   varchar(255) NOT NULL DEFAULT '',
+    reason      text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_task_events_task_id_created_at ON task_events (task_id, created_at);
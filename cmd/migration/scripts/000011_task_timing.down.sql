@@ -0,0 +1,5 @@
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS queued_at,
+    DROP COLUMN IF EXISTS started_at,
+    DROP COLUMN IF EXISTS finished_at,
+    DROP COLUMN IF EXISTS worker_id;
@@ -0,0 +1,9 @@
+ALTER TABLE tasks
+    ADD COLUMN queued_at   timestamptz,
+    ADD COLUMN started_at  timestamptz,
+    ADD COLUMN finished_at timestamptz,
+    ADD COLUMN worker_id   varchar(255) NOT NULL DEFAULT '';
+
+UPDATE tasks
+SET queued_at = created_at
+WHERE status = 'PENDING';
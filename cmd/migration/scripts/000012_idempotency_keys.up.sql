@@ -0,0 +1,13 @@
+CREATE TABLE idempotency_keys
+(
+    key          varchar(255) PRIMARY KEY,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    expires_at   timestamptz NOT NULL,
+
+    request_hash varchar(64) NOT NULL,
+    task_id      uuid        NOT NULL,
+    status_code  integer     NOT NULL,
+    response     jsonb       NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
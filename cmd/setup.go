@@ -2,46 +2,94 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	adminHttpAdaptor "github.com/thealiakbari/task-pool-system/internal/adapters/inbound/http/admin"
+	scheduleHttpAdaptor "github.com/thealiakbari/task-pool-system/internal/adapters/inbound/http/schedule"
 	taskHttpAdaptor "github.com/thealiakbari/task-pool-system/internal/adapters/inbound/http/task"
 	taskOutboundRepo "github.com/thealiakbari/task-pool-system/internal/adapters/outbound/db/pg"
+	taskMetrics "github.com/thealiakbari/task-pool-system/internal/adapters/outbound/metrics"
+	adminApp "github.com/thealiakbari/task-pool-system/internal/application/admin"
+	scheduleApp "github.com/thealiakbari/task-pool-system/internal/application/schedule"
 	taskApp "github.com/thealiakbari/task-pool-system/internal/application/task"
+	scheduleService "github.com/thealiakbari/task-pool-system/internal/domain/schedule"
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/runner"
 	taskService "github.com/thealiakbari/task-pool-system/internal/domain/task"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/bus"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/notifier"
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/pool"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/scheduler"
+	scheduleInterface "github.com/thealiakbari/task-pool-system/internal/ports/inbound/schedule"
 	taskInterface "github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+	scheduleRepo "github.com/thealiakbari/task-pool-system/internal/ports/outbound/schedule"
 	taskRepo "github.com/thealiakbari/task-pool-system/internal/ports/outbound/task"
 	"github.com/thealiakbari/task-pool-system/pkg/common/config"
 	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+	"github.com/thealiakbari/task-pool-system/pkg/common/health"
 	"github.com/thealiakbari/task-pool-system/pkg/common/i18next"
 	"github.com/thealiakbari/task-pool-system/pkg/common/logger"
+	"github.com/thealiakbari/task-pool-system/pkg/common/metrics"
 	"golang.org/x/text/language"
+	"time"
 )
 
+// eventBuffer is the number of events a streaming client may fall behind before it is dropped
+const eventBuffer = 256
+
 type RepositoryStorage struct {
-	taskRepo taskRepo.TaskRepository
+	taskRepo     taskRepo.TaskRepository
+	scheduleRepo scheduleRepo.ScheduleRepository
 }
 
 type ServiceStorage struct {
-	taskSvc taskInterface.TaskService
+	taskSvc     taskInterface.TaskService
+	scheduleSvc scheduleInterface.ScheduleService
+}
+
+// WorkerStorage holds the background components which run the tasks, they start with Start once the
+// database is migrated
+type WorkerStorage struct {
+	pool          *pool.Pool
+	scheduler     *scheduler.Scheduler
+	runner        *runner.Runner
+	poolDeps      pool.WorkerDeps
+	schedulerDeps scheduler.Deps
+	runnerDeps    runner.Deps
 }
 
 type ApplicationStorage struct {
-	taskApp taskApp.TaskHttpApp
+	taskApp         taskApp.TaskHttpApp
+	subscriptionApp taskApp.TaskSubscriptionApp
+	scheduleApp     scheduleApp.ScheduleHttpApp
+	poolApp         adminApp.PoolHttpApp
 }
 
 type HttpAdaptorStorage struct {
-	TaskAdaptor taskHttpAdaptor.Adaptor
+	TaskAdaptor         taskHttpAdaptor.Adaptor
+	SubscriptionAdaptor taskHttpAdaptor.SubscriptionAdaptor
+	ScheduleAdaptor     scheduleHttpAdaptor.Adaptor
+	AdminAdaptor        adminHttpAdaptor.Adaptor
 }
 
+// SetupConfig carries everything the executor runs with, Cancel stops the background components started from Ctx.
+// Health holds the checks of the subsystems, a new subsystem adds its own checks to it. Events carries the recorded
+// status changes to the streaming clients and Completions wakes up the requests which wait for a task to settle.
 type SetupConfig struct {
 	Ctx                context.Context
+	Cancel             context.CancelFunc
 	Conf               *config.AppConfig
 	Logger             logger.Logger
 	DB                 db.DBWrapper
+	Health             *health.Registry
+	Events             *bus.Bus
+	Completions        *notifier.Notifier
+	Workers            Workers
 	HttpAdaptorStorage HttpAdaptorStorage
 }
 
 func Setup() *SetupConfig {
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
 	conf := config.LoadConfig("./config/config.yml")
 
 	log, err := logger.New(
@@ -58,59 +106,167 @@ func Setup() *SetupConfig {
 		panic(err)
 	}
 
-	logInfra := log.CloneAsInfra()
-	err = db.Migrate(conf.DB.Postgres, logInfra)
+	gormDB, err := db.NewPostgresConn(ctx, conf.DB.Postgres)
 	if err != nil {
-		logInfra.Panicf("Migration failed: %s\n", err.Error())
+		panic(err)
 	}
-	logInfra.Info("Migrations successfully done.")
 
-	gormDB, err := db.NewPostgresConn(ctx, conf.DB.Postgres)
+	dbw := db.NewDBWrapper(gormDB)
+
+	sqlDB, err := gormDB.DB()
 	if err != nil {
 		panic(err)
 	}
+	if err = metrics.RegisterDBStats(prometheus.DefaultRegisterer, conf.DB.Postgres.Name, sqlDB); err != nil {
+		panic(err)
+	}
 
-	dbw := db.NewDBWrapper(gormDB)
+	checks := health.NewRegistry()
+	checks.AddReadiness("database", sqlDB.PingContext)
 
-	repos := NewRepositoryStorage(dbw)
-	services := NewServiceStorage(log, repos)
+	handlers := NewHandlerRegistry()
 
-	httpApps := NewHttpAppStorage(dbw, services)
+	repos := NewRepositoryStorage(dbw)
+	events := bus.New(eventBuffer)
+	completions := notifier.New(ctx, conf.Wait.PollInterval)
+	services := NewServiceStorage(log, repos, handlers, events, completions)
+	completions.Start(notifier.Deps{TaskService: services.taskSvc})
+	workers := NewWorkerStorage(ctx, dbw, conf.Pool, services, handlers)
+	checks.AddLiveness("pool", workers.pool.Live)
+	checks.AddReadiness("pool", workers.pool.Ready)
+	httpApps := NewHttpAppStorage(dbw, services, workers, events, completions)
 	httpAdaptors := NewHttpAdaptorStorage(httpApps)
 
 	return &SetupConfig{
 		Ctx:                ctx,
+		Cancel:             cancel,
 		Conf:               conf,
 		Logger:             log,
 		DB:                 dbw,
+		Health:             checks,
+		Events:             events,
+		Completions:        completions,
+		Workers:            workers,
 		HttpAdaptorStorage: httpAdaptors,
 	}
 }
 
+// Start starts the pool and the schedulers which feed it
+func (w WorkerStorage) Start() {
+	w.pool.Start(w.poolDeps)
+	w.scheduler.Start(w.schedulerDeps)
+	w.runner.Start(w.runnerDeps)
+}
+
+// Stop stops the schedulers which feed the pool, then gives the running tasks until ctx ends to finish
+func (w WorkerStorage) Stop(ctx context.Context) error {
+	return stopWorkers(ctx, w.pool, w.runner, w.scheduler)
+}
+
+// stopWorkers shuts the feeders down before it stops the pool, so no task is submitted to a stopping pool
+func stopWorkers(ctx context.Context, pool interface{ Stop(context.Context) error }, feeders ...interface{ Shutdown() }) error {
+	for _, feeder := range feeders {
+		feeder.Shutdown()
+	}
+
+	return pool.Stop(ctx)
+}
+
+// NewHandlerRegistry registers the handler of every task type the workers can run
+func NewHandlerRegistry() *handler.Registry {
+	handlers := handler.NewRegistry()
+	handlers.MustRegister(handler.TypeSleep, handler.Sleep())
+
+	return handlers
+}
+
+// NewWorkerStorage builds the pool and the schedulers which feed it, they all stop once ctx is done
+func NewWorkerStorage(
+	ctx context.Context,
+	dbw db.DBWrapper,
+	poolConf config.Pool,
+	services ServiceStorage,
+	handlers *handler.Registry,
+) WorkerStorage {
+	if err := poolConf.Validate(); err != nil {
+		panic(fmt.Errorf("invalid pool configuration: %w", err))
+	}
+
+	policy, err := pool.ParseSubmitPolicy(poolConf.SubmitPolicy)
+	if err != nil {
+		panic(err)
+	}
+
+	poolWorker := pool.New(ctx, poolConf.Workers, poolConf.QueueSize)
+	poolWorker.SetDefaultTimeout(poolConf.DefaultTimeout)
+	if err := poolWorker.SetScaling(poolConf.MinWorkers, poolConf.MaxWorkers, poolConf.IdleTimeout); err != nil {
+		panic(fmt.Errorf("invalid pool configuration: %w", err))
+	}
+	poolWorker.SetSubmitPolicy(policy, poolConf.SubmitTimeout)
+	poolMetrics := taskMetrics.NewPoolMetrics(prometheus.DefaultRegisterer, poolWorker)
+
+	return WorkerStorage{
+		pool:      poolWorker,
+		scheduler: scheduler.New(ctx, time.Second),
+		runner:    runner.New(ctx, time.Second),
+		poolDeps: pool.WorkerDeps{
+			TaskService: services.taskSvc,
+			Handlers:    handlers,
+			Observers:   []pool.Observer{poolMetrics},
+		},
+		schedulerDeps: scheduler.Deps{
+			TaskService: services.taskSvc,
+			Pool:        poolWorker,
+		},
+		runnerDeps: runner.Deps{
+			DB:              dbw,
+			ScheduleService: services.scheduleSvc,
+			TaskService:     services.taskSvc,
+			Pool:            poolWorker,
+		},
+	}
+}
+
 func NewHttpAppStorage(
 	db db.DBWrapper,
 	services ServiceStorage,
+	workers WorkerStorage,
+	events *bus.Bus,
+	completions *notifier.Notifier,
 ) ApplicationStorage {
-	poolWorker := pool.New(context.Background(), 10, 10)
-	poolWorker.Start(pool.WorkerDeps{
-		TaskService: services.taskSvc,
-	})
 	return ApplicationStorage{
-		taskApp: taskApp.NewTaskHttpApp(services.taskSvc, db, poolWorker),
+		taskApp:         taskApp.NewTaskHttpApp(services.taskSvc, db, workers.pool, events, completions),
+		subscriptionApp: taskApp.NewTaskSubscriptionApp(events),
+		scheduleApp:     scheduleApp.NewScheduleHttpApp(services.scheduleSvc, db),
+		poolApp:         adminApp.NewPoolHttpApp(workers.pool),
 	}
 }
 
 func NewRepositoryStorage(db db.DBWrapper) RepositoryStorage {
 	return RepositoryStorage{
-		taskRepo: taskOutboundRepo.NewTaskRepository(db),
+		taskRepo:     taskOutboundRepo.NewTaskRepository(db),
+		scheduleRepo: taskOutboundRepo.NewScheduleRepository(db),
 	}
 }
 
-func NewServiceStorage(log logger.Logger, repos RepositoryStorage) ServiceStorage {
-	taskSvc := taskService.NewTaskService(taskService.TaskConfig{Logger: log, TaskRepo: repos.taskRepo})
+func NewServiceStorage(
+	log logger.Logger,
+	repos RepositoryStorage,
+	handlers *handler.Registry,
+	events *bus.Bus,
+	completions *notifier.Notifier,
+) ServiceStorage {
+	taskSvc := taskService.NewTaskService(taskService.TaskConfig{
+		Logger:     log,
+		TaskRepo:   repos.taskRepo,
+		Handlers:   handlers,
+		Publishers: []taskRepo.EventPublisher{events, completions},
+	})
+	scheduleSvc := scheduleService.NewScheduleService(scheduleService.ScheduleConfig{Logger: log, ScheduleRepo: repos.scheduleRepo, Handlers: handlers})
 
 	return ServiceStorage{
-		taskSvc: taskSvc,
+		taskSvc:     taskSvc,
+		scheduleSvc: scheduleSvc,
 	}
 }
 
@@ -118,6 +274,9 @@ func NewHttpAdaptorStorage(
 	httpApps ApplicationStorage,
 ) HttpAdaptorStorage {
 	return HttpAdaptorStorage{
-		TaskAdaptor: taskHttpAdaptor.Adaptor{TaskHttpApp: httpApps.taskApp},
+		TaskAdaptor:         taskHttpAdaptor.Adaptor{TaskHttpApp: httpApps.taskApp},
+		SubscriptionAdaptor: taskHttpAdaptor.SubscriptionAdaptor{TaskSubscriptionApp: httpApps.subscriptionApp},
+		ScheduleAdaptor:     scheduleHttpAdaptor.Adaptor{ScheduleHttpApp: httpApps.scheduleApp},
+		AdminAdaptor:        adminHttpAdaptor.Adaptor{PoolHttpApp: httpApps.poolApp},
 	}
 }
@@ -0,0 +1,20 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	service "github.com/thealiakbari/task-pool-system/internal/application/admin"
+)
+
+type Adaptor struct {
+	service.PoolHttpApp
+}
+
+func (a Adaptor) RegisterRoutes(r *gin.RouterGroup) {
+	apiPool := r.Group("/admin/pool")
+
+	apiPool.GET("", a.MakeGetStats())
+	apiPool.PUT("/workers", a.MakeSetWorkers())
+	apiPool.POST("/pause", a.MakePause())
+	apiPool.POST("/resume", a.MakeResume())
+	apiPool.POST("/drain", a.MakeDrain())
+}
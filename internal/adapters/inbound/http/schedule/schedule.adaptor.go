@@ -0,0 +1,24 @@
+package schedule
+
+import (
+	"github.com/gin-gonic/gin"
+	service "github.com/thealiakbari/task-pool-system/internal/application/schedule"
+)
+
+type Adaptor struct {
+	service.ScheduleHttpApp
+}
+
+func (a Adaptor) RegisterRoutes(r *gin.RouterGroup) {
+	apiSchedule := r.Group("/schedules")
+
+	apiSchedule.POST("", a.MakeCreate())
+	apiSchedule.PUT("/:id", a.MakeUpdate())
+	apiSchedule.POST("/:id/pause", a.MakePause())
+	apiSchedule.POST("/:id/resume", a.MakeResume())
+
+	apiSchedule.GET("", a.MakeGetAll())
+	apiSchedule.GET("/:id", a.MakeGetById())
+
+	apiSchedule.DELETE("/:id", a.MakeDelete())
+}
@@ -0,0 +1,15 @@
+package poll
+
+import (
+	"github.com/gin-gonic/gin"
+	service "github.com/thealiakbari/task-pool-system/internal/application/task"
+)
+
+// SubscriptionAdaptor serves the WebSocket which pushes the task events to the subscribed clients
+type SubscriptionAdaptor struct {
+	service.TaskSubscriptionApp
+}
+
+func (a SubscriptionAdaptor) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/tasks/subscribe", a.MakeSubscribe())
+}
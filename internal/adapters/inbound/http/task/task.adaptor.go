@@ -14,8 +14,16 @@ func (a Adaptor) RegisterRoutes(r *gin.RouterGroup) {
 
 	apiTask.POST("", a.MakeCreate())
 	apiTask.PUT("/:id", a.MakeUpdate())
+	apiTask.POST("/:id/cancel", a.MakeCancel())
+	apiTask.POST("/:id/redrive", a.MakeRedrive())
 
+	apiTask.GET("", a.MakeGetAll())
+	apiTask.GET("/dead-letters", a.MakeGetDeadLetters())
+	apiTask.GET("/stream", a.MakeStreamAll())
 	apiTask.GET("/:id", a.MakeGetById())
+	apiTask.GET("/:id/events", a.MakeGetEvents())
+	apiTask.GET("/:id/stream", a.MakeStream())
+	apiTask.GET("/:id/wait", a.MakeWait())
 
 	apiTask.DELETE("/:id", a.MakeDelete())
 	apiTask.DELETE("/purge/:id", a.MakePurge())
@@ -0,0 +1,127 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+	"github.com/thealiakbari/task-pool-system/internal/ports/outbound/schedule"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+)
+
+type ScheduleConfig struct {
+	db db.DBWrapper
+}
+
+func NewScheduleRepository(db db.DBWrapper) schedule.ScheduleRepository {
+	return ScheduleConfig{
+		db: db,
+	}
+}
+
+func (u ScheduleConfig) Create(ctx context.Context, in entity.Schedule) (res entity.Schedule, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Save(&in).Error
+	if err != nil {
+		return entity.Schedule{}, err
+	}
+
+	return in, nil
+}
+
+func (u ScheduleConfig) Update(ctx context.Context, in entity.Schedule) (err error) {
+	err = db.GormConnection(ctx, u.db.DB).Save(&in).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u ScheduleConfig) FindByIdOrEmpty(ctx context.Context, id string) (res entity.Schedule, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Model(&res).Find(&res, "id = ?", id).Limit(1).Error
+	if err != nil {
+		return entity.Schedule{}, err
+	}
+
+	return res, nil
+}
+
+func (u ScheduleConfig) Delete(ctx context.Context, id string) (err error) {
+	err = db.GormConnection(ctx, u.db.DB).Delete(&entity.Schedule{}, "id = ?", id).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u ScheduleConfig) FilterFind(ctx context.Context, query []any, order string, limit int, offset int) (res []entity.Schedule, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Model(&res).Order(order).
+		Limit(limit).
+		Offset(offset).
+		Find(&res, query...).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (u ScheduleConfig) FilterCount(ctx context.Context, query []any) (res int64, err error) {
+	countQuery := db.GormConnection(ctx, u.db.DB).Model(&entity.Schedule{})
+	if len(query) > 1 {
+		countQuery = countQuery.Where(query[0], query[1:]...)
+	} else if len(query) == 1 {
+		countQuery = countQuery.Where(query[0])
+	}
+
+	err = countQuery.Count(&res).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (u ScheduleConfig) FindDue(ctx context.Context, now time.Time, limit int) (res []entity.Schedule, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Model(&res).
+		Where("paused = ? AND next_run_at <= ?", false, now).
+		Order("next_run_at").
+		Limit(limit).
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Advance moves the next run of the schedule forward only when it still is at from, so exactly one
+// executor fires every run even when several of them read the same due schedule
+func (u ScheduleConfig) Advance(ctx context.Context, id uuid.UUID, from time.Time, next time.Time, lastRunAt *time.Time) (ok bool, err error) {
+	columns := map[string]any{"next_run_at": next}
+	if lastRunAt != nil {
+		columns["last_run_at"] = *lastRunAt
+	}
+
+	result := db.GormConnection(ctx, u.db.DB).Model(&entity.Schedule{}).
+		Where("id = ? AND next_run_at = ? AND paused = ?", id, from, false).
+		UpdateColumns(columns)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (u ScheduleConfig) SetLastTask(ctx context.Context, id uuid.UUID, taskId uuid.UUID) (err error) {
+	err = db.GormConnection(ctx, u.db.DB).Model(&entity.Schedule{}).
+		Where("id = ?", id).
+		UpdateColumn("last_task_id", taskId).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
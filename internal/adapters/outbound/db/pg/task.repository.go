@@ -2,12 +2,18 @@ package pg
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
 	"github.com/thealiakbari/task-pool-system/internal/ports/outbound/task"
 	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+	"gorm.io/gorm"
 )
 
+// leaseFree matches the rows which are not leased or whose lease has expired
+const leaseFree = "(lease_expires_at IS NULL OR lease_expires_at < now())"
+
 type TaskConfig struct {
 	db db.DBWrapper
 }
@@ -36,6 +42,21 @@ func (u TaskConfig) Update(ctx context.Context, in entity.Task) (err error) {
 	return nil
 }
 
+// UpdateFrom saves the given columns of the task, every column when none is given, only while its stored status
+// is still from, ok is false when another writer moved the task first
+func (u TaskConfig) UpdateFrom(ctx context.Context, in entity.Task, from entity.Status, columns ...string) (ok bool, err error) {
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+
+	result := db.GormConnection(ctx, u.db.DB).Model(&in).Where("status = ?", from).Select(columns).Updates(&in)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
 func (u TaskConfig) FindByIdOrEmpty(ctx context.Context, id string) (res entity.Task, err error) {
 	err = db.GormConnection(ctx, u.db.DB).Model(&res).Order("created_at desc").Find(&res, "id = ?", id).Limit(1).Error
 	if err != nil {
@@ -99,3 +120,192 @@ func (u TaskConfig) FilterCount(ctx context.Context, query []any) (res int64, er
 
 	return res, nil
 }
+
+// ClaimPending leases up to limit pending tasks whose queued_at has passed to the owner, so a retry waits out its
+// backoff, rows locked by a concurrent claim are skipped. The tasks are ordered the same way as the pool queue, by
+// priority aged by the time since the task was last queued.
+func (u TaskConfig) ClaimPending(ctx context.Context, owner string, limit int, ttl time.Duration) (res []entity.Task, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Raw(`
+		UPDATE tasks SET lease_owner = ?, lease_expires_at = now() + make_interval(secs => ?)
+		WHERE id IN (
+			SELECT id FROM tasks
+			WHERE status = ? AND deleted_at IS NULL AND `+leaseFree+`
+				AND (queued_at IS NULL OR queued_at <= now())
+			ORDER BY priority * ? - EXTRACT(EPOCH FROM COALESCE(queued_at, now())) DESC, COALESCE(queued_at, now())
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		owner, ttl.Seconds(), entity.StatusPending, entity.PriorityAging.Seconds(), limit,
+	).Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Lease takes the lease of a pending task when it is free or already owned by the owner
+func (u TaskConfig) Lease(ctx context.Context, id uuid.UUID, owner string, ttl time.Duration) (ok bool, err error) {
+	result := db.GormConnection(ctx, u.db.DB).Model(&entity.Task{}).
+		Where("id = ? AND status = ?", id, entity.StatusPending).
+		Where("(lease_owner = ? OR "+leaseFree+")", owner).
+		UpdateColumns(map[string]any{
+			"lease_owner":      owner,
+			"lease_expires_at": gorm.Expr("now() + make_interval(secs => ?)", ttl.Seconds()),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (u TaskConfig) RenewLeases(ctx context.Context, owner string, ids []uuid.UUID, ttl time.Duration) (err error) {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	err = db.GormConnection(ctx, u.db.DB).Model(&entity.Task{}).
+		Where("id IN ? AND lease_owner = ?", ids, owner).
+		Where("status IN ?", []entity.Status{entity.StatusPending, entity.StatusRunning}).
+		UpdateColumn("lease_expires_at", gorm.Expr("now() + make_interval(secs => ?)", ttl.Seconds())).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseLeases frees the leases the owner holds on pending tasks, so other pools can claim them at once
+func (u TaskConfig) ReleaseLeases(ctx context.Context, owner string) (err error) {
+	err = db.GormConnection(ctx, u.db.DB).Model(&entity.Task{}).
+		Where("lease_owner = ? AND status = ?", owner, entity.StatusPending).
+		UpdateColumns(map[string]any{
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReclaimExpired moves the running tasks whose owner stopped renewing the lease back to pending and returns them
+func (u TaskConfig) ReclaimExpired(ctx context.Context) (res []entity.Task, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Raw(`
+		UPDATE tasks SET status = ?, lease_owner = '', lease_expires_at = NULL, queued_at = now(), updated_at = now()
+		WHERE status = ? AND deleted_at IS NULL AND `+leaseFree+`
+		RETURNING *`,
+		entity.StatusPending, entity.StatusRunning,
+	).Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// PromoteDue moves up to limit scheduled tasks whose run_at has passed to pending, rows locked by a concurrent
+// promotion are skipped so every due task is returned to exactly one caller
+func (u TaskConfig) PromoteDue(ctx context.Context, limit int) (res []entity.Task, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Raw(`
+		UPDATE tasks SET status = ?, queued_at = now(), updated_at = now()
+		WHERE id IN (
+			SELECT id FROM tasks
+			WHERE status = ? AND deleted_at IS NULL AND run_at <= now()
+			ORDER BY run_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		entity.StatusPending, entity.StatusScheduled, limit,
+	).Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (u TaskConfig) CreateEvents(ctx context.Context, in []entity.TaskEvent) (err error) {
+	if len(in) == 0 {
+		return nil
+	}
+
+	err = db.GormConnection(ctx, u.db.DB).Create(&in).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindIdempotencyKey returns the live key or an empty one when the key is unknown or expired
+func (u TaskConfig) FindIdempotencyKey(ctx context.Context, key string) (res entity.IdempotencyKey, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Model(&res).Where("key = ? AND expires_at > now()", key).Limit(1).Find(&res).Error
+	if err != nil {
+		return entity.IdempotencyKey{}, err
+	}
+
+	return res, nil
+}
+
+// SaveIdempotencyKey stores the key unless a live row holds it already, an expired row is taken over.
+// A concurrent save of the same key waits for the first one to commit and reports false.
+func (u TaskConfig) SaveIdempotencyKey(ctx context.Context, in entity.IdempotencyKey) (ok bool, err error) {
+	result := db.GormConnection(ctx, u.db.DB).Exec(`
+		INSERT INTO idempotency_keys (key, created_at, expires_at, request_hash, task_id, status_code, response)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			created_at = excluded.created_at, expires_at = excluded.expires_at, request_hash = excluded.request_hash,
+			task_id = excluded.task_id, status_code = excluded.status_code, response = excluded.response
+		WHERE idempotency_keys.expires_at <= now()`,
+		in.Key, in.CreatedAt, in.ExpiresAt, in.RequestHash, in.TaskId, in.StatusCode, string(in.Response),
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// FindEventsAfter returns the events which match the filter and were recorded after the given one, in the
+// order they happened. An unknown event yields no events.
+func (u TaskConfig) FindEventsAfter(ctx context.Context, filter entity.EventFilter, after string, limit int) (res []entity.TaskEvent, err error) {
+	query := db.GormConnection(ctx, u.db.DB).Model(&res).
+		Where("(created_at, id) > (SELECT created_at, id FROM task_events WHERE id = ?)", after)
+	if filter.TaskId != uuid.Nil {
+		query = query.Where("task_id = ?", filter.TaskId)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("to_status IN ?", filter.Statuses)
+	}
+
+	err = query.Order("created_at, id").Limit(limit).Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (u TaskConfig) FindEventByIdOrEmpty(ctx context.Context, id string) (res entity.TaskEvent, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Model(&res).Find(&res, "id = ?", id).Limit(1).Error
+	if err != nil {
+		return entity.TaskEvent{}, err
+	}
+
+	return res, nil
+}
+
+// FindEvents returns the events of the task in the order they happened
+func (u TaskConfig) FindEvents(ctx context.Context, taskId string) (res []entity.TaskEvent, err error) {
+	err = db.GormConnection(ctx, u.db.DB).Model(&res).Order("created_at, id").Find(&res, "task_id = ?", taskId).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,255 @@
+package pg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/pkg/common/config"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+)
+
+func setupTestDB(t *testing.T) db.DBWrapper {
+	conf := config.LoadConfig("../../../../../config/config.yml")
+	gormDB, err := db.NewPostgresConn(context.Background(), conf.DB.Postgres)
+	if err != nil {
+		t.Skipf("postgres is not reachable: %v", err)
+	}
+	dbw := db.NewDBWrapper(gormDB)
+
+	return dbw
+}
+
+func TestTaskRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	testDB := setupTestDB(t)
+	repo := NewTaskRepository(testDB)
+
+	// Create
+	item := entity.Task{
+		Title:       "Test",
+		Description: "Test description",
+		Status:      entity.StatusPending,
+	}
+	created, err := repo.Create(ctx, item)
+	assert.NoError(t, err)
+	assert.Equal(t, item.Title, created.Title)
+
+	// FindByIdOrEmpty
+	found, err := repo.FindByIdOrEmpty(ctx, created.Id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, created.Id, found.Id)
+
+	// Update
+	created.Title = "UpdatedTask"
+	err = repo.Update(ctx, created)
+	assert.NoError(t, err)
+
+	updated, err := repo.FindByIdOrEmpty(ctx, created.Id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "UpdatedTask", updated.Title)
+
+	// FindByIds
+	list, err := repo.FindByIds(ctx, []string{created.Id.String()})
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	// FilterFind
+	results, err := repo.FilterFind(ctx, []any{"id = ? AND title LIKE ?", created.Id, "%Task%"}, "created_at desc", 10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	// FilterCount
+	count, err := repo.FilterCount(ctx, []any{"id = ? AND title LIKE ?", created.Id, "%Task%"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// Delete
+	err = repo.Delete(ctx, created.Id.String())
+	assert.NoError(t, err)
+
+	_, err = repo.FindByIdOrEmpty(ctx, created.Id.String())
+	assert.NoError(t, err) // should return empty entity, not fail
+
+	// Purge
+	// Re-create and then purge
+	item2 := entity.Task{
+		Title:       "TempTask",
+		Description: "Temp description",
+		Status:      entity.StatusPending,
+	}
+	created2, _ := repo.Create(ctx, item2)
+	err = repo.Purge(ctx, created2.Id.String())
+	assert.NoError(t, err)
+}
+
+func TestTaskRepository_IdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	testDB := setupTestDB(t)
+	repo := NewTaskRepository(testDB)
+
+	key := entity.NewIdempotencyKey(uuid.NewString(), "hash", uuid.New(), 201, []byte(`{"title":"Test"}`))
+	ok, err := repo.SaveIdempotencyKey(ctx, key)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	found, err := repo.FindIdempotencyKey(ctx, key.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, key.TaskId, found.TaskId)
+	assert.JSONEq(t, string(key.Response), string(found.Response))
+
+	// A live key is not taken over
+	ok, err = repo.SaveIdempotencyKey(ctx, entity.NewIdempotencyKey(key.Key, "other", uuid.New(), 201, []byte(`{}`)))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// An expired key is
+	expired := entity.NewIdempotencyKey(uuid.NewString(), "hash", uuid.New(), 201, []byte(`{}`))
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = repo.SaveIdempotencyKey(ctx, expired)
+	assert.NoError(t, err)
+
+	found, err = repo.FindIdempotencyKey(ctx, expired.Key)
+	assert.NoError(t, err)
+	assert.Empty(t, found.Key)
+
+	ok, err = repo.SaveIdempotencyKey(ctx, entity.NewIdempotencyKey(expired.Key, "other", uuid.New(), 201, []byte(`{}`)))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestTaskRepository_FindEventsAfter(t *testing.T) {
+	ctx := context.Background()
+	testDB := setupTestDB(t)
+	repo := NewTaskRepository(testDB)
+
+	created, err := repo.Create(ctx, entity.Task{Title: "Test", Description: "Test description", Status: entity.StatusPending})
+	assert.NoError(t, err)
+	defer func() { _ = repo.Purge(ctx, created.Id.String()) }()
+
+	queued := entity.NewTaskEvent(created, "", entity.Transition{Reason: "created"})
+	running := created
+	running.Status = entity.StatusRunning
+	started := entity.NewTaskEvent(running, entity.StatusPending, entity.Transition{Reason: "attempt 1 started"})
+	completed := running
+	completed.Status = entity.StatusCompleted
+	finished := entity.NewTaskEvent(completed, entity.StatusRunning, entity.Transition{Reason: "completed"})
+	assert.NoError(t, repo.CreateEvents(ctx, []entity.TaskEvent{queued, started, finished}))
+
+	events, err := repo.FindEventsAfter(ctx, entity.EventFilter{TaskId: created.Id}, queued.Id.String(), 10)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, started.Id, events[0].Id)
+		assert.Equal(t, finished.Id, events[1].Id)
+	}
+
+	events, err = repo.FindEventsAfter(ctx, entity.EventFilter{TaskId: created.Id, Statuses: []entity.Status{entity.StatusCompleted}}, queued.Id.String(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	events, err = repo.FindEventsAfter(ctx, entity.EventFilter{TaskId: created.Id}, uuid.NewString(), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestTaskRepository_ClaimPendingWaitsForQueuedAt(t *testing.T) {
+	ctx := context.Background()
+	testDB := setupTestDB(t)
+	repo := NewTaskRepository(testDB)
+	owner := "test/" + uuid.NewString()
+
+	queuedAt := time.Now().Add(time.Hour)
+	created, err := repo.Create(ctx, entity.Task{Title: "Test", Description: "Test description", Status: entity.StatusPending, QueuedAt: &queuedAt})
+	assert.NoError(t, err)
+	defer func() { _ = repo.Purge(ctx, created.Id.String()) }()
+	defer func() { _ = repo.ReleaseLeases(ctx, owner) }()
+
+	claimed, err := repo.ClaimPending(ctx, owner, 1000, time.Minute)
+	assert.NoError(t, err)
+	for _, task := range claimed {
+		assert.NotEqual(t, created.Id, task.Id, "a retry is claimed before its backoff is over")
+	}
+	assert.NoError(t, repo.ReleaseLeases(ctx, owner))
+
+	queuedAt = time.Now().Add(-time.Second)
+	created.QueuedAt = &queuedAt
+	assert.NoError(t, repo.Update(ctx, created))
+
+	claimed, err = repo.ClaimPending(ctx, owner, 1000, time.Minute)
+	assert.NoError(t, err)
+	ids := make([]uuid.UUID, 0, len(claimed))
+	for _, task := range claimed {
+		ids = append(ids, task.Id)
+	}
+	assert.Contains(t, ids, created.Id)
+}
+
+func TestTaskRepository_UpdateFrom(t *testing.T) {
+	ctx := context.Background()
+	testDB := setupTestDB(t)
+	repo := NewTaskRepository(testDB)
+
+	created, err := repo.Create(ctx, entity.Task{Title: "Test", Description: "Test description", Status: entity.StatusRunning})
+	assert.NoError(t, err)
+	defer func() { _ = repo.Purge(ctx, created.Id.String()) }()
+
+	cancelled := created
+	cancelled.Status = entity.StatusCancelled
+	ok, err := repo.UpdateFrom(ctx, cancelled, entity.StatusRunning)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// The worker read the task while it was running and loses to the cancel
+	completed := created
+	completed.Status = entity.StatusCompleted
+	ok, err = repo.UpdateFrom(ctx, completed, entity.StatusRunning)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	found, err := repo.FindByIdOrEmpty(ctx, created.Id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, entity.StatusCancelled, found.Status)
+}
+
+func TestTaskRepository_UpdateFromKeepsEditsOutsideTheColumns(t *testing.T) {
+	ctx := context.Background()
+	testDB := setupTestDB(t)
+	repo := NewTaskRepository(testDB)
+
+	created, err := repo.Create(ctx, entity.Task{Title: "Test", Description: "Test description", Status: entity.StatusPending})
+	assert.NoError(t, err)
+	defer func() { _ = repo.Purge(ctx, created.Id.String()) }()
+
+	started := created
+	started.Status = entity.StatusRunning
+	started.Attempts = 1
+	ok, err := repo.UpdateFrom(ctx, started, entity.StatusPending, entity.StateColumns...)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// The task is edited while the worker runs it from its own copy
+	edited := started
+	edited.Title = "Edited"
+	edited.Description = "Edited description"
+	ok, err = repo.UpdateFrom(ctx, edited, entity.StatusRunning)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	completed := started
+	completed.Status = entity.StatusCompleted
+	completed.Result = []byte(`{"ok":true}`)
+	ok, err = repo.UpdateFrom(ctx, completed, entity.StatusRunning, entity.StateColumns...)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	found, err := repo.FindByIdOrEmpty(ctx, created.Id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, entity.StatusCompleted, found.Status)
+	assert.Equal(t, 1, found.Attempts)
+	assert.JSONEq(t, `{"ok":true}`, string(found.Result))
+	assert.Equal(t, "Edited", found.Title)
+	assert.Equal(t, "Edited description", found.Description)
+}
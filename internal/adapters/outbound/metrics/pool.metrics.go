@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/pool"
+)
+
+// taskBuckets spans from 10ms to about 45 minutes
+var taskBuckets = prometheus.ExponentialBuckets(0.01, 4, 10)
+
+// PoolMetrics exports the state of the pool, read from its stats on every scrape, and the outcome, run
+// time and wait time of every task attempt, which it observes as a pool.Observer
+type PoolMetrics struct {
+	outcomes *prometheus.CounterVec
+	runTime  *prometheus.HistogramVec
+	waitTime *prometheus.HistogramVec
+}
+
+func NewPoolMetrics(reg prometheus.Registerer, p *pool.Pool) *PoolMetrics {
+	m := &PoolMetrics{
+		outcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_outcomes_total",
+			Help: "Number of task attempts by the status they ended with and the task type.",
+		}, []string{"status", "type"}),
+		runTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "task_run_seconds",
+			Help:    "Time a task attempt ran by task type.",
+			Buckets: taskBuckets,
+		}, []string{"type"}),
+		waitTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "task_wait_seconds",
+			Help:    "Time a task waited in the queue before a worker started it by task type.",
+			Buckets: taskBuckets,
+		}, []string{"type"}),
+	}
+
+	reg.MustRegister(
+		m.outcomes,
+		m.runTime,
+		m.waitTime,
+		poolGauge("pool_queue_length", "Number of tasks waiting in the queue.", p, func(s pool.Stats) float64 {
+			return float64(s.QueueLength)
+		}),
+		poolGauge("pool_queue_capacity", "Number of tasks the queue holds.", p, func(s pool.Stats) float64 {
+			return float64(s.QueueCapacity)
+		}),
+		poolGauge("pool_workers", "Number of live workers.", p, func(s pool.Stats) float64 {
+			return float64(s.Workers)
+		}),
+		poolGauge("pool_busy_workers", "Number of workers running a task.", p, func(s pool.Stats) float64 {
+			return float64(s.BusyWorkers)
+		}),
+		poolGauge("pool_retrying_tasks", "Number of tasks waiting for their retry backoff.", p, func(s pool.Stats) float64 {
+			return float64(s.Retrying)
+		}),
+		poolGauge("pool_paused", "1 while the pool does not dispatch tasks.", p, func(s pool.Stats) float64 {
+			return boolValue(s.Paused)
+		}),
+		poolGauge("pool_draining", "1 while the pool does not accept tasks.", p, func(s pool.Stats) float64 {
+			return boolValue(s.Draining)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "pool_panics_total",
+			Help: "Number of panics recovered from handlers and workers.",
+		}, func() float64 {
+			return float64(p.Stats().Panics)
+		}),
+	)
+
+	return m
+}
+
+func (m *PoolMetrics) TaskStarted(task entity.Task) {
+	m.waitTime.WithLabelValues(task.Type).Observe(task.WaitTime().Seconds())
+}
+
+func (m *PoolMetrics) TaskFinished(task entity.Task) {
+	m.outcomes.WithLabelValues(string(task.Status), task.Type).Inc()
+	m.runTime.WithLabelValues(task.Type).Observe(task.RunTime(time.Now()).Seconds())
+}
+
+func poolGauge(name string, help string, p *pool.Pool, value func(pool.Stats) float64) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, func() float64 {
+		return value(p.Stats())
+	})
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
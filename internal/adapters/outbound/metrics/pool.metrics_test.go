@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/pool"
+)
+
+func TestPoolMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p := pool.New(context.Background(), 2, 5)
+	m := NewPoolMetrics(reg, p)
+
+	queuedAt := time.Now().Add(-3 * time.Second)
+	startedAt := queuedAt.Add(time.Second)
+	finishedAt := startedAt.Add(500 * time.Millisecond)
+	task := entity.Task{Type: "sleep", Status: entity.StatusRunning, QueuedAt: &queuedAt, StartedAt: &startedAt}
+
+	m.TaskStarted(task)
+	task.Status = entity.StatusCompleted
+	task.FinishedAt = &finishedAt
+	m.TaskFinished(task)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.outcomes.WithLabelValues("COMPLETED", "sleep")))
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP pool_queue_capacity Number of tasks the queue holds.
+# TYPE pool_queue_capacity gauge
+pool_queue_capacity 5
+`), "pool_queue_capacity"))
+
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		switch family.GetName() {
+		case "task_run_seconds":
+			assert.Equal(t, 0.5, family.GetMetric()[0].GetHistogram().GetSampleSum())
+		case "task_wait_seconds":
+			assert.Equal(t, 1.0, family.GetMetric()[0].GetHistogram().GetSampleSum())
+		}
+	}
+}
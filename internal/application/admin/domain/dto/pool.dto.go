@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"context"
+	"time"
+
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+// PoolStats describes the pool at the time of the request, Throughput is the number of tasks
+// processed per second over the last minute and Panics counts the recovered panics
+type PoolStats struct {
+	MinWorkers    int     `json:"minWorkers"`
+	MaxWorkers    int     `json:"maxWorkers"`
+	TargetWorkers int     `json:"targetWorkers"`
+	Workers       int     `json:"workers"`
+	BusyWorkers   int     `json:"busyWorkers"`
+	IdleWorkers   int     `json:"idleWorkers"`
+	QueueLength   int     `json:"queueLength"`
+	QueueCapacity int     `json:"queueCapacity"`
+	Retrying      int     `json:"retrying"`
+	Paused        bool    `json:"paused"`
+	Draining      bool    `json:"draining"`
+	Processed     int64   `json:"processed"`
+	Throughput    float64 `json:"throughput" example:"2.5"`
+	Panics        int64   `json:"panics"`
+}
+
+// SetWorkersRequest sets the worker count the pool keeps while it is idle, it must be within the
+// configured min and max workers
+type SetWorkersRequest struct {
+	Workers int `json:"workers" validate:"required,min=1" minimum:"1" example:"8"`
+}
+
+func (s SetWorkersRequest) Validate(ctx context.Context) error {
+	return validation.Validate(ctx, s)
+}
+
+// DrainPoolRequest bounds the time the drain request waits for the pool to run out of work, the pool
+// keeps draining after the timeout
+type DrainPoolRequest struct {
+	Timeout *time.Duration `form:"timeout" validate:"omitempty,gt=0,max=10m" swaggertype:"string" example:"30s"`
+}
+
+func (d DrainPoolRequest) Validate(ctx context.Context) error {
+	return validation.Validate(ctx, d)
+}
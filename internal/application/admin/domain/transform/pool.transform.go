@@ -0,0 +1,25 @@
+package transform
+
+import (
+	"github.com/thealiakbari/task-pool-system/internal/application/admin/domain/dto"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/pool"
+)
+
+func PoolStatsToDto(in pool.Stats) dto.PoolStats {
+	return dto.PoolStats{
+		MinWorkers:    in.MinWorkers,
+		MaxWorkers:    in.MaxWorkers,
+		TargetWorkers: in.TargetWorkers,
+		Workers:       in.Workers,
+		BusyWorkers:   in.BusyWorkers,
+		IdleWorkers:   max(in.Workers-in.BusyWorkers, 0),
+		QueueLength:   in.QueueLength,
+		QueueCapacity: in.QueueCapacity,
+		Retrying:      in.Retrying,
+		Paused:        in.Paused,
+		Draining:      in.Draining,
+		Processed:     in.Processed,
+		Throughput:    in.Throughput,
+		Panics:        in.Panics,
+	}
+}
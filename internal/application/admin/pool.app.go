@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thealiakbari/task-pool-system/internal/application/admin/domain/dto"
+	"github.com/thealiakbari/task-pool-system/internal/application/admin/domain/transform"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/pool"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+)
+
+const defaultDrainTimeout = time.Minute
+
+type PoolHttpApp struct {
+	pool *pool.Pool
+}
+
+func NewPoolHttpApp(pool *pool.Pool) PoolHttpApp {
+	return PoolHttpApp{
+		pool: pool,
+	}
+}
+
+// MakeGetStats
+// @Schemes
+// @Summary Get Pool Stats
+// @Description This api for reading the queue length, busy and idle workers and the throughput of the pool
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Success 200  {object}  dto.PoolStats
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /admin/pool [get]
+func (t PoolHttpApp) MakeGetStats() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		appErr.OKResponse(ginCtx, transform.PoolStatsToDto(t.pool.Stats()))
+	}
+}
+
+// MakeSetWorkers
+// @Schemes
+// @Summary Set Pool Workers
+// @Description This api for changing the worker count of the pool without a restart, removed workers finish their current task first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param  body body dto.SetWorkersRequest true "Contains information to set data"
+// @Success 200  {object}  dto.PoolStats
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /admin/pool/workers [put]
+func (t PoolHttpApp) MakeSetWorkers() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		var req dto.SetWorkersRequest
+		if err := ginCtx.ShouldBindJSON(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := req.Validate(ginCtx.Request.Context()); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		err := t.pool.SetWorkers(req.Workers)
+		if errors.Is(err, pool.ErrInvalidWorkerCount) {
+			stats := t.pool.Stats()
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: fmt.Sprintf("workers must be between %d and %d", stats.MinWorkers, stats.MaxWorkers),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		appErr.OKResponse(ginCtx, transform.PoolStatsToDto(t.pool.Stats()))
+	}
+}
+
+// MakePause
+// @Schemes
+// @Summary Pause Pool
+// @Description This api for stopping the dispatch of queued tasks, running tasks finish and new tasks are still accepted
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Success 200  {object}  dto.PoolStats
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /admin/pool/pause [post]
+func (t PoolHttpApp) MakePause() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		t.pool.Pause()
+		appErr.OKResponse(ginCtx, transform.PoolStatsToDto(t.pool.Stats()))
+	}
+}
+
+// MakeResume
+// @Schemes
+// @Summary Resume Pool
+// @Description This api for restarting the dispatch of a paused pool, a drained pool accepts tasks again
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Success 200  {object}  dto.PoolStats
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /admin/pool/resume [post]
+func (t PoolHttpApp) MakeResume() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		t.pool.Resume()
+		appErr.OKResponse(ginCtx, transform.PoolStatsToDto(t.pool.Stats()))
+	}
+}
+
+// MakeDrain
+// @Schemes
+// @Summary Drain Pool
+// @Description This api for refusing new tasks and waiting until the queued and running tasks are done, it answers 202 when the pool is still draining at the timeout
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param  query query dto.DrainPoolRequest false "Drain timeout"
+// @Success 200  {object}  dto.PoolStats
+// @Success 202  {object}  dto.PoolStats
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /admin/pool/drain [post]
+func (t PoolHttpApp) MakeDrain() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		var req dto.DrainPoolRequest
+		if err := ginCtx.ShouldBindQuery(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := req.Validate(ginCtx.Request.Context()); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		timeout := defaultDrainTimeout
+		if req.Timeout != nil {
+			timeout = *req.Timeout
+		}
+
+		ctx, cancel := context.WithTimeout(ginCtx.Request.Context(), timeout)
+		defer cancel()
+
+		if err := t.pool.Drain(ctx); err != nil {
+			appErr.AcceptedResponse(ginCtx, transform.PoolStatsToDto(t.pool.Stats()))
+			return
+		}
+
+		appErr.OKResponse(ginCtx, transform.PoolStatsToDto(t.pool.Stats()))
+	}
+}
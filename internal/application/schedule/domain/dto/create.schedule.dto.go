@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"context"
+	"encoding/json"
+
+	taskDto "github.com/thealiakbari/task-pool-system/internal/application/task/domain/dto"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+type TaskTemplate struct {
+	Title       string               `json:"title" validate:"required"`
+	Description string               `json:"description" validate:"required"`
+	Type        string               `json:"type" validate:"omitempty,max=64" example:"sleep"`
+	Payload     json.RawMessage      `json:"payload" validate:"omitempty,max=65536" swaggertype:"object"`
+	Priority    *int                 `json:"priority" validate:"omitempty,min=0,max=9" minimum:"0" maximum:"9" example:"5"`
+	Retry       *taskDto.RetryPolicy `json:"retry" validate:"omitempty"`
+	Timeout     string               `json:"timeout" validate:"omitempty,duration" example:"30s"`
+}
+
+type CreateScheduleRequest struct {
+	Name       string       `json:"name" validate:"required"`
+	Expression string       `json:"expression" validate:"required" example:"*/5 * * * *"`
+	Timezone   string       `json:"timezone" validate:"omitempty,timezone" example:"UTC"`
+	Task       TaskTemplate `json:"task" validate:"required"`
+	CatchUp    string       `json:"catchUp" validate:"omitempty,oneof=SKIP ONCE ALL" enums:"SKIP,ONCE,ALL" example:"ONCE"`
+	NoOverlap  bool         `json:"noOverlap"`
+	Paused     bool         `json:"paused"`
+}
+
+func (c CreateScheduleRequest) Validate(ctx context.Context) error {
+	return validation.Validate(ctx, c)
+}
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"context"
+
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+type GetScheduleRequest struct {
+	Names  []string `form:"names"`
+	Paused *bool    `form:"paused"`
+	SortBy string   `form:"sortBy" validate:"omitempty,oneof=createdAt updatedAt name nextRunAt" enums:"createdAt,updatedAt,name,nextRunAt"`
+
+	request.SortSpec   `json:"-"`
+	request.Pagination `json:"-"`
+}
+
+func (g GetScheduleRequest) Validate(ctx context.Context) error {
+	return validation.Validate(ctx, g)
+}
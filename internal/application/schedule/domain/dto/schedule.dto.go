@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	taskDto "github.com/thealiakbari/task-pool-system/internal/application/task/domain/dto"
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+)
+
+type Schedule struct {
+	Id         uuid.UUID            `json:"id"`
+	Name       string               `json:"name"`
+	Expression string               `json:"expression"`
+	Timezone   string               `json:"timezone"`
+	Task       Template             `json:"task"`
+	CatchUp    entity.CatchUpPolicy `json:"catchUp"`
+	NoOverlap  bool                 `json:"noOverlap"`
+	Paused     bool                 `json:"paused"`
+	NextRunAt  *time.Time           `json:"nextRunAt,omitempty"`
+	LastRunAt  *time.Time           `json:"lastRunAt,omitempty"`
+	LastTaskId *uuid.UUID           `json:"lastTaskId,omitempty"`
+	CreatedAt  time.Time            `json:"createdAt"`
+	UpdatedAt  time.Time            `json:"updatedAt"`
+}
+
+type Template struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Type        string              `json:"type"`
+	Payload     json.RawMessage     `json:"payload,omitempty" swaggertype:"object"`
+	Priority    int                 `json:"priority"`
+	Retry       taskDto.RetryPolicy `json:"retry"`
+	Timeout     string              `json:"timeout,omitempty"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"context"
+
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+type UpdateScheduleRequest struct {
+	Name       string       `json:"name" validate:"required"`
+	Expression string       `json:"expression" validate:"required" example:"*/5 * * * *"`
+	Timezone   string       `json:"timezone" validate:"omitempty,timezone" example:"UTC"`
+	Task       TaskTemplate `json:"task" validate:"required"`
+	CatchUp    string       `json:"catchUp" validate:"omitempty,oneof=SKIP ONCE ALL" enums:"SKIP,ONCE,ALL" example:"ONCE"`
+	NoOverlap  bool         `json:"noOverlap"`
+}
+
+func (u UpdateScheduleRequest) Validate(ctx context.Context) error {
+	return validation.Validate(ctx, u)
+}
@@ -0,0 +1,161 @@
+package transform
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/application/schedule/domain/dto"
+	taskTransform "github.com/thealiakbari/task-pool-system/internal/application/task/domain/transform"
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+	taskEntity "github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+)
+
+func CreateScheduleRequestToEntity(in dto.CreateScheduleRequest) (out entity.Schedule, err error) {
+	out = entity.Schedule{
+		Name:       in.Name,
+		Expression: in.Expression,
+		Timezone:   in.Timezone,
+		CatchUp:    entity.CatchUpPolicy(in.CatchUp),
+		NoOverlap:  in.NoOverlap,
+		Paused:     in.Paused,
+	}
+
+	if out.Timezone == "" {
+		out.Timezone = "UTC"
+	}
+
+	if out.CatchUp == "" {
+		out.CatchUp = entity.CatchUpOnce
+	}
+
+	out.Template, err = TaskTemplateDtoToEntity(in.Task)
+	if err != nil {
+		return out, err
+	}
+
+	return out, nil
+}
+
+func UpdateScheduleRequestToEntity(in dto.UpdateScheduleRequest, id string) (out entity.Schedule, err error) {
+	out, err = CreateScheduleRequestToEntity(dto.CreateScheduleRequest{
+		Name:       in.Name,
+		Expression: in.Expression,
+		Timezone:   in.Timezone,
+		Task:       in.Task,
+		CatchUp:    in.CatchUp,
+		NoOverlap:  in.NoOverlap,
+	})
+	if err != nil {
+		return out, err
+	}
+
+	out.Id, err = uuid.Parse(id)
+	if err != nil {
+		return out, err
+	}
+
+	return out, nil
+}
+
+func TaskTemplateDtoToEntity(in dto.TaskTemplate) (out entity.TaskTemplate, err error) {
+	out = entity.TaskTemplate{
+		Title:       in.Title,
+		Description: in.Description,
+		Type:        in.Type,
+		Priority:    taskEntity.DefaultPriority,
+		Retry: taskEntity.RetryPolicy{
+			MaxAttempts: 1,
+			Backoff:     taskEntity.BackoffFixed,
+		},
+	}
+
+	if out.Type == "" {
+		out.Type = handler.TypeSleep
+	}
+
+	out.Payload, _, err = taskTransform.ResolvePayload(out.Type, in.Payload)
+	if err != nil {
+		return out, err
+	}
+
+	if in.Priority != nil {
+		out.Priority = *in.Priority
+	}
+
+	if in.Retry != nil {
+		out.Retry, err = taskTransform.RetryPolicyDtoToEntity(*in.Retry)
+		if err != nil {
+			return out, err
+		}
+	}
+
+	if in.Timeout != "" {
+		out.Timeout, err = time.ParseDuration(in.Timeout)
+		if err != nil {
+			return out, err
+		}
+	}
+
+	return out, nil
+}
+
+func GetScheduleRequestToFilter(in dto.GetScheduleRequest) entity.ScheduleFilter {
+	out := entity.ScheduleFilter{
+		Names:    in.Names,
+		Paused:   in.Paused,
+		SortBy:   in.SortBy,
+		SortType: request.SortTypeDESC,
+	}
+
+	if out.SortBy == "" {
+		out.SortBy = "createdAt"
+	}
+
+	if in.SortType != nil {
+		out.SortType = *in.SortType
+	}
+
+	return out
+}
+
+func ScheduleEntityToScheduleDto(in entity.Schedule) dto.Schedule {
+	out := dto.Schedule{
+		Id:         in.Id,
+		Name:       in.Name,
+		Expression: in.Expression,
+		Timezone:   in.Timezone,
+		Task: dto.Template{
+			Title:       in.Template.Title,
+			Description: in.Template.Description,
+			Type:        in.Template.Type,
+			Payload:     in.Template.Payload,
+			Priority:    in.Template.Priority,
+			Retry:       taskTransform.RetryPolicyEntityToDto(in.Template.Retry),
+		},
+		CatchUp:    in.CatchUp,
+		NoOverlap:  in.NoOverlap,
+		Paused:     in.Paused,
+		NextRunAt:  in.NextRunAt,
+		LastRunAt:  in.LastRunAt,
+		LastTaskId: in.LastTaskId,
+		CreatedAt:  in.CreatedAt,
+		UpdatedAt:  in.UpdatedAt,
+	}
+
+	if in.Template.Timeout > 0 {
+		out.Task.Timeout = in.Template.Timeout.String()
+	}
+
+	return out
+}
+
+func SchedulesEntityToSchedulesDto(in []entity.Schedule) []dto.Schedule {
+	items := make([]dto.Schedule, 0, len(in))
+	for _, v := range in {
+		items = append(items, ScheduleEntityToScheduleDto(v))
+	}
+
+	return items
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/application/schedule/domain/dto"
+	"github.com/thealiakbari/task-pool-system/internal/application/schedule/domain/transform"
+	scheduleService "github.com/thealiakbari/task-pool-system/internal/domain/schedule"
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+	scheduleInterface "github.com/thealiakbari/task-pool-system/internal/ports/inbound/schedule"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+	"github.com/thealiakbari/task-pool-system/pkg/common/utiles"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+type ScheduleHttpApp struct {
+	scheduleSvc scheduleInterface.ScheduleService
+	db          db.DBWrapper
+}
+
+func NewScheduleHttpApp(scheduleSvc scheduleInterface.ScheduleService, db db.DBWrapper) ScheduleHttpApp {
+	return ScheduleHttpApp{
+		db:          db,
+		scheduleSvc: scheduleSvc,
+	}
+}
+
+// MakeCreate
+// @Schemes
+// @Summary Create Schedule
+// @Description This api for create a recurring schedule which fires a task on every run of its cron expression
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param  body body dto.CreateScheduleRequest true "Contains information to set data"
+// @Success 201  {object}  dto.Schedule
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /schedules [post]
+func (s ScheduleHttpApp) MakeCreate() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		var req dto.CreateScheduleRequest
+		if err := ginCtx.ShouldBindJSON(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := req.Validate(ginCtx.Request.Context()); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		createReq, err := transform.CreateScheduleRequestToEntity(req)
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		tx, ctx, err := db.BeginTx(ginCtx.Request.Context(), s.db.DB)
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		defer func() {
+			if err != nil {
+				if err := tx.Rollback().Error; err != nil {
+					appErr.HandelError(ginCtx, &appErr.Error{
+						Cause:   err,
+						Message: err.Error(),
+						Class:   appErr.EConflict,
+					})
+					return
+				}
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+		}()
+
+		scheduleEntityResp, err := s.scheduleSvc.Create(ctx, createReq)
+		if err != nil {
+			return
+		}
+
+		if err = db.Commit(ctx, tx); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		appErr.CreatedResponse(ginCtx, transform.ScheduleEntityToScheduleDto(scheduleEntityResp))
+	}
+}
+
+// MakeUpdate
+// @Schemes
+// @Summary Update Schedule
+// @Description This api for update the definition of a schedule, its next run is planned again
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param id path string true "Schedule Id"
+// @Param  body body dto.UpdateScheduleRequest true "Contains information to set data"
+// @Success 200  {object}  dto.Schedule
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
+// @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /schedules/{id} [put]
+func (s ScheduleHttpApp) MakeUpdate() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		var req dto.UpdateScheduleRequest
+		if err := ginCtx.ShouldBindJSON(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := req.Validate(ginCtx.Request.Context()); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		updateReq, err := transform.UpdateScheduleRequestToEntity(req, ginCtx.Param("id"))
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		tx, ctx, err := db.BeginTx(ginCtx.Request.Context(), s.db.DB)
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		defer func() {
+			if err != nil {
+				if err := tx.Rollback().Error; err != nil {
+					appErr.HandelError(ginCtx, &appErr.Error{
+						Cause:   err,
+						Message: err.Error(),
+						Class:   appErr.EConflict,
+					})
+					return
+				}
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+		}()
+
+		scheduleEntityResp, err := s.scheduleSvc.Update(ctx, updateReq)
+		if err != nil {
+			return
+		}
+
+		if err = db.Commit(ctx, tx); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		appErr.OKResponse(ginCtx, transform.ScheduleEntityToScheduleDto(scheduleEntityResp))
+	}
+}
+
+// MakePause
+// @Schemes
+// @Summary Pause Schedule
+// @Description This api for pause a schedule, the runs which fall in the pause are not fired
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param id path string true "Schedule Id"
+// @Success 200  {object}  dto.Schedule
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /schedules/{id}/pause [post]
+func (s ScheduleHttpApp) MakePause() gin.HandlerFunc {
+	return s.makeToggle(s.scheduleSvc.Pause)
+}
+
+// MakeResume
+// @Schemes
+// @Summary Resume Schedule
+// @Description This api for resume a paused schedule from its next run
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param id path string true "Schedule Id"
+// @Success 200  {object}  dto.Schedule
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /schedules/{id}/resume [post]
+func (s ScheduleHttpApp) MakeResume() gin.HandlerFunc {
+	return s.makeToggle(s.scheduleSvc.Resume)
+}
+
+func (s ScheduleHttpApp) makeToggle(toggle func(ctx context.Context, id string) (entity.Schedule, error)) gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		tx, ctx, err := db.BeginTx(ginCtx.Request.Context(), s.db.DB)
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		defer func() {
+			if err != nil {
+				if err := tx.Rollback().Error; err != nil {
+					appErr.HandelError(ginCtx, &appErr.Error{
+						Cause:   err,
+						Message: err.Error(),
+						Class:   appErr.EConflict,
+					})
+					return
+				}
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+		}()
+
+		scheduleEntityResp, err := toggle(ctx, ginCtx.Param("id"))
+		if err != nil {
+			return
+		}
+
+		if err = db.Commit(ctx, tx); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		appErr.OKResponse(ginCtx, transform.ScheduleEntityToScheduleDto(scheduleEntityResp))
+	}
+}
+
+// MakeDelete
+// @Schemes
+// @Summary Delete Schedule
+// @Description This api for delete schedule, the tasks it already created are kept
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param id path string true "Schedule Id"
+// @Success 204
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /schedules/{id} [delete]
+func (s ScheduleHttpApp) MakeDelete() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		tx, ctx, err := db.BeginTx(ginCtx.Request.Context(), s.db.DB)
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		defer func() {
+			if err != nil {
+				if err := tx.Rollback().Error; err != nil {
+					appErr.HandelError(ginCtx, &appErr.Error{
+						Cause:   err,
+						Message: err.Error(),
+						Class:   appErr.EConflict,
+					})
+					return
+				}
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+		}()
+
+		err = s.scheduleSvc.Delete(ctx, ginCtx.Param("id"))
+		if err != nil {
+			return
+		}
+
+		if err = db.Commit(ctx, tx); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		appErr.NoContentResponse(ginCtx)
+	}
+}
+
+// MakeGetById
+// @Schemes
+// @Summary Get Schedule By Id
+// @Description This api for schedule by id
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param id path string true "Schedule Id"
+// @Success 200  {object} dto.Schedule
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /schedules/{id} [get]
+func (s ScheduleHttpApp) MakeGetById() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		scheduleEntityResp, err := s.scheduleSvc.GetByIdOrEmpty(ginCtx.Request.Context(), ginCtx.Param("id"))
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		if scheduleEntityResp.Id == uuid.Nil {
+			appErr.HandelError(ginCtx, scheduleService.ErrScheduleNotFound)
+			return
+		}
+
+		appErr.OKResponse(ginCtx, transform.ScheduleEntityToScheduleDto(scheduleEntityResp))
+	}
+}
+
+// MakeGetAll
+// @Schemes
+// @Summary Get Schedules
+// @Description This api for list schedules with filters, sorting and pagination
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param  query query dto.GetScheduleRequest false "Filters, sorting and pagination"
+// @Success 200  {object}  appErr.ListResponse{items=[]dto.Schedule}
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /schedules [get]
+func (s ScheduleHttpApp) MakeGetAll() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		var req dto.GetScheduleRequest
+		if err := ginCtx.ShouldBindQuery(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := validation.BindStringSlices(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := req.Validate(ginCtx.Request.Context()); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		pagination, err := utiles.PaginationNormalizer(req.Pagination, ginCtx.Request.Context())
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		filter := transform.GetScheduleRequestToFilter(req)
+		schedules, total, err := s.scheduleSvc.List(ginCtx.Request.Context(), filter, utiles.PaginationToPortion(pagination))
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		appErr.OKResponse(ginCtx, appErr.PaginationAndSortListResponse(
+			transform.SchedulesEntityToSchedulesDto(schedules),
+			total,
+			int64(pagination.PageSize),
+			int64(pagination.Page),
+			filter.SortBy,
+			filter.SortType,
+		))
+	}
+}
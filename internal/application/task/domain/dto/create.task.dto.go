@@ -2,15 +2,40 @@ package dto
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
 
 	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
 )
 
+// CreateTaskRequest describes a new task, Type selects the handler which runs it and the payload is
+// handed to that handler as is. Timeout bounds a single run, without it the pool default applies.
 type CreateTaskRequest struct {
-	Title       string `json:"title" validate:"required"`
-	Description string `json:"description" validate:"required"`
+	Title       string          `json:"title" validate:"required"`
+	Description string          `json:"description" validate:"required"`
+	Type        string          `json:"type" validate:"omitempty,max=64" example:"sleep"`
+	Payload     json.RawMessage `json:"payload" validate:"omitempty,max=65536" swaggertype:"object"`
+	Priority    *int            `json:"priority" validate:"omitempty,min=0,max=9" minimum:"0" maximum:"9" example:"5"`
+	Retry       *RetryPolicy    `json:"retry" validate:"omitempty"`
+	Timeout     string          `json:"timeout" validate:"omitempty,duration" example:"30s"`
+	// RunAt and Delay postpone the task, a time in the past runs it right away
+	RunAt *time.Time `json:"runAt" validate:"omitempty"`
+	Delay string     `json:"delay" validate:"omitempty,duration,excluded_with=RunAt" example:"10m"`
 }
 
 func (c CreateTaskRequest) Validate(ctx context.Context) error {
 	return validation.Validate(ctx, c)
 }
+
+// Hash identifies the request for its idempotency key, requests with the same fields have the same hash
+func (c CreateTaskRequest) Hash() (string, error) {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
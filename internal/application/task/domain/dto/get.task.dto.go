@@ -2,17 +2,38 @@ package dto
 
 import (
 	"context"
+	"time"
 
 	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
 )
 
 type GetTaskRequest struct {
-	Ids   []string `form:"ids"`
-	Title []string `json:"titles"`
+	Ids          []string       `form:"ids"`
+	Titles       []string       `form:"titles"`
+	Types        []string       `form:"types"`
+	Statuses     []string       `form:"statuses" validate:"omitempty,dive,oneof=SCHEDULED PENDING RUNNING COMPLETED FAILED CANCELLED DEAD_LETTER TIMED_OUT"`
+	CreatedFrom  *time.Time     `form:"createdFrom"`
+	CreatedTo    *time.Time     `form:"createdTo"`
+	UpdatedFrom  *time.Time     `form:"updatedFrom"`
+	UpdatedTo    *time.Time     `form:"updatedTo"`
+	DurationFrom *time.Duration `form:"durationFrom" swaggertype:"string" example:"1s"`
+	DurationTo   *time.Duration `form:"durationTo" swaggertype:"string" example:"5s"`
+	SortBy       string         `form:"sortBy" validate:"omitempty,oneof=createdAt updatedAt title status duration priority runAt queuedAt startedAt finishedAt" enums:"createdAt,updatedAt,title,status,duration,priority,runAt,queuedAt,startedAt,finishedAt"`
 
+	request.SortSpec   `json:"-"`
 	request.Pagination `json:"-"`
 }
 
 func (g GetTaskRequest) Validate(ctx context.Context) error {
-	return nil
+	return validation.Validate(ctx, g)
+}
+
+type GetDeadLetterTaskRequest struct {
+	request.SortSpec   `json:"-"`
+	request.Pagination `json:"-"`
+}
+
+func (g GetDeadLetterTaskRequest) Validate(ctx context.Context) error {
+	return validation.Validate(ctx, g)
 }
@@ -0,0 +1,9 @@
+package dto
+
+type RetryPolicy struct {
+	MaxAttempts int     `json:"maxAttempts" validate:"min=1,max=100" example:"3"`
+	Backoff     string  `json:"backoff" validate:"omitempty,oneof=FIXED EXPONENTIAL" enums:"FIXED,EXPONENTIAL" example:"EXPONENTIAL"`
+	Delay       string  `json:"delay" validate:"omitempty,duration" example:"1s"`
+	MaxDelay    string  `json:"maxDelay" validate:"omitempty,duration" example:"1m"`
+	Jitter      float64 `json:"jitter" validate:"min=0,max=1" example:"0.2"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+type StreamTaskRequest struct {
+	Statuses []string `form:"status" validate:"omitempty,dive,oneof=SCHEDULED PENDING RUNNING COMPLETED FAILED CANCELLED DEAD_LETTER TIMED_OUT"`
+}
+
+func (s StreamTaskRequest) Validate(ctx context.Context) error {
+	return validation.Validate(ctx, s)
+}
+
+// TaskStreamEvent is the data of a server-sent event, the id of the event is sent as its SSE id as well
+type TaskStreamEvent struct {
+	Id         uuid.UUID        `json:"id"`
+	TaskId     uuid.UUID        `json:"taskId"`
+	Kind       entity.EventKind `json:"kind" enums:"SCHEDULED,QUEUED,STARTED,ATTEMPT_FAILED,TIMED_OUT,RETRIED,REDRIVEN,COMPLETED,FAILED,CANCELLED,DEAD_LETTERED"`
+	FromStatus entity.Status    `json:"fromStatus,omitempty"`
+	ToStatus   entity.Status    `json:"toStatus"`
+	WorkerId   string           `json:"workerId,omitempty"`
+	Reason     string           `json:"reason,omitempty"`
+	At         time.Time        `json:"at"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"context"
+
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+const (
+	SubscriptionActionSubscribe   = "subscribe"
+	SubscriptionActionUnsubscribe = "unsubscribe"
+
+	SubscriptionReplySubscribed = "subscribed"
+	SubscriptionReplyEvent      = "event"
+	SubscriptionReplyError      = "error"
+)
+
+// SubscriptionRequest adds task ids and statuses to the subscription of a connection or removes them, an event
+// is sent when its task is subscribed to or the status it moves the task to is
+type SubscriptionRequest struct {
+	Action   string   `json:"action" validate:"required,oneof=subscribe unsubscribe" enums:"subscribe,unsubscribe"`
+	Ids      []string `json:"ids" validate:"omitempty,max=1000,dive,uuid"`
+	Statuses []string `json:"statuses" validate:"omitempty,dive,oneof=SCHEDULED PENDING RUNNING COMPLETED FAILED CANCELLED DEAD_LETTER TIMED_OUT"`
+}
+
+func (s SubscriptionRequest) Validate(ctx context.Context) error {
+	return validation.Validate(ctx, s)
+}
+
+// SubscriptionReply is a message of the server: the whole subscription after every request, an event which
+// matches the subscription or the error of a request
+type SubscriptionReply struct {
+	Type     string                          `json:"type" enums:"subscribed,event,error"`
+	Ids      []string                        `json:"ids,omitempty"`
+	Statuses []string                        `json:"statuses,omitempty"`
+	Event    *TaskStreamEvent                `json:"event,omitempty"`
+	Error    string                          `json:"error,omitempty"`
+	Causes   []validation.ResponseValidation `json:"causes,omitempty"`
+}
@@ -1,18 +1,37 @@
 package dto
 
 import (
+	"encoding/json"
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Task is the public view of a task, WaitTime and RunTime cover its last attempt: the time it spent in the
+// queue and the time it ran
 type Task struct {
-	Id          uuid.UUID     `json:"id"`
-	Title       string        `json:"title"`
-	Description string        `json:"description"`
-	Status      entity.Status `json:"status"`
-	Duration    time.Duration `json:"duration"`
-	CreatedAt   time.Time     `json:"createdAt"`
-	UpdatedAt   time.Time     `json:"updatedAt"`
+	Id          uuid.UUID       `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Status      entity.Status   `json:"status"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+	Result      json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Error       string          `json:"error,omitempty"`
+	Duration    time.Duration   `json:"duration"`
+	Timeout     string          `json:"timeout,omitempty"`
+	Priority    int             `json:"priority"`
+	RunAt       *time.Time      `json:"runAt,omitempty"`
+	Retry       RetryPolicy     `json:"retry"`
+	Attempts    int             `json:"attempts"`
+	LastError   string          `json:"lastError,omitempty"`
+	WorkerId    string          `json:"workerId,omitempty"`
+	QueuedAt    *time.Time      `json:"queuedAt,omitempty"`
+	StartedAt   *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time      `json:"finishedAt,omitempty"`
+	WaitTime    string          `json:"waitTime,omitempty" example:"250ms"`
+	RunTime     string          `json:"runTime,omitempty" example:"3.2s"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
 }
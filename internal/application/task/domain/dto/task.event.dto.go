@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+)
+
+// TaskTimeline lists the lifecycle of a task, every event carries the time elapsed since the previous one
+type TaskTimeline struct {
+	TaskId uuid.UUID     `json:"taskId"`
+	Status entity.Status `json:"status"`
+	Events []TaskEvent   `json:"events"`
+	// Total is the time between the first and the last event
+	Total string `json:"total" example:"3.2s"`
+}
+
+type TaskEvent struct {
+	Id         uuid.UUID        `json:"id"`
+	Kind       entity.EventKind `json:"kind" enums:"SCHEDULED,QUEUED,STARTED,ATTEMPT_FAILED,TIMED_OUT,RETRIED,REDRIVEN,COMPLETED,FAILED,CANCELLED,DEAD_LETTERED"`
+	FromStatus entity.Status    `json:"fromStatus,omitempty"`
+	ToStatus   entity.Status    `json:"toStatus"`
+	WorkerId   string           `json:"workerId,omitempty"`
+	Reason     string           `json:"reason,omitempty"`
+	At         time.Time        `json:"at"`
+	Elapsed    string           `json:"elapsed" example:"1.5s"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import (
+	"context"
+	"time"
+
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+// WaitTaskRequest bounds the time the request waits for the task to settle
+type WaitTaskRequest struct {
+	Timeout *time.Duration `form:"timeout" validate:"omitempty,gt=0,max=5m" swaggertype:"string" example:"30s"`
+}
+
+func (w WaitTaskRequest) Validate(ctx context.Context) error {
+	return validation.Validate(ctx, w)
+}
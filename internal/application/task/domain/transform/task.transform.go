@@ -1,48 +1,242 @@
 package transform
 
 import (
-	"github.com/google/uuid"
+	"encoding/json"
 	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/dto"
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
 	"time"
 )
 
-func CreateTaskRequestToEntity(in dto.CreateTaskRequest) entity.Task {
-	out := entity.Task{
+func CreateTaskRequestToEntity(in dto.CreateTaskRequest) (out entity.Task, err error) {
+	out = entity.Task{
 		Title:       in.Title,
 		Description: in.Description,
 		Status:      entity.StatusPending,
-		Duration:    time.Duration(1+time.Now().Unix()%5) * time.Second,
+		Type:        in.Type,
+		Priority:    entity.DefaultPriority,
+		Retry: entity.RetryPolicy{
+			MaxAttempts: 1,
+			Backoff:     entity.BackoffFixed,
+		},
 	}
 
-	return out
+	if out.Type == "" {
+		out.Type = handler.TypeSleep
+	}
+
+	out.Payload, out.Duration, err = ResolvePayload(out.Type, in.Payload)
+	if err != nil {
+		return out, err
+	}
+
+	if in.Priority != nil {
+		out.Priority = *in.Priority
+	}
+
+	if in.Retry != nil {
+		out.Retry, err = RetryPolicyDtoToEntity(*in.Retry)
+		if err != nil {
+			return out, err
+		}
+	}
+
+	if in.Timeout != "" {
+		out.Timeout, err = time.ParseDuration(in.Timeout)
+		if err != nil {
+			return out, err
+		}
+	}
+
+	runAt := in.RunAt
+	if in.Delay != "" {
+		delay, err := time.ParseDuration(in.Delay)
+		if err != nil {
+			return out, err
+		}
+		at := time.Now().Add(delay)
+		runAt = &at
+	}
+
+	if runAt != nil && runAt.After(time.Now()) {
+		out.Status = entity.StatusScheduled
+		out.RunAt = runAt
+	}
+
+	return out, nil
 }
 
-func UpdateTaskRequestToEntity(in dto.UpdateTaskRequest, id string) (out entity.Task, err error) {
-	out = entity.Task{
-		Title:       in.Title,
-		Description: in.Description,
+// ResolvePayload checks the payload of the built-in sleep tasks and returns their duration, a sleep task
+// without a payload simulates 1-5s of work. The payloads of the other types are returned as is.
+func ResolvePayload(taskType string, payload json.RawMessage) (json.RawMessage, time.Duration, error) {
+	if taskType != handler.TypeSleep {
+		return payload, 0, nil
+	}
+
+	if len(payload) == 0 {
+		duration := time.Duration(1+time.Now().Unix()%5) * time.Second
+		return handler.NewSleepPayload(duration), duration, nil
 	}
 
-	idUUID, err := uuid.Parse(id)
+	duration, err := handler.ParseSleepPayload(payload)
 	if err != nil {
-		return out, err
+		return nil, 0, err
+	}
+
+	return payload, duration, nil
+}
+
+func RetryPolicyDtoToEntity(in dto.RetryPolicy) (out entity.RetryPolicy, err error) {
+	out = entity.RetryPolicy{
+		MaxAttempts: in.MaxAttempts,
+		Backoff:     entity.BackoffType(in.Backoff),
+		Jitter:      in.Jitter,
+	}
+
+	if out.Backoff == "" {
+		out.Backoff = entity.BackoffFixed
+	}
+
+	if in.Delay != "" {
+		out.Delay, err = time.ParseDuration(in.Delay)
+		if err != nil {
+			return out, err
+		}
+	}
+
+	if in.MaxDelay != "" {
+		out.MaxDelay, err = time.ParseDuration(in.MaxDelay)
+		if err != nil {
+			return out, err
+		}
 	}
 
-	out.Id = idUUID
 	return out, nil
 }
 
+// UpdateTaskRequestToEntity applies the request on the stored task, the fields it does not carry are kept
+func UpdateTaskRequestToEntity(in dto.UpdateTaskRequest, current entity.Task) entity.Task {
+	current.Title = in.Title
+	current.Description = in.Description
+	return current
+}
+
+func GetTaskRequestToFilter(in dto.GetTaskRequest) entity.TaskFilter {
+	out := entity.TaskFilter{
+		Ids:    in.Ids,
+		Titles: in.Titles,
+		Types:  in.Types,
+		CreatedAt: request.DateRange{
+			From: in.CreatedFrom,
+			To:   in.CreatedTo,
+		},
+		UpdatedAt: request.DateRange{
+			From: in.UpdatedFrom,
+			To:   in.UpdatedTo,
+		},
+		SortBy:   in.SortBy,
+		SortType: request.SortTypeDESC,
+	}
+
+	for _, status := range in.Statuses {
+		out.Statuses = append(out.Statuses, entity.Status(status))
+	}
+
+	if in.DurationFrom != nil {
+		from := int64(*in.DurationFrom)
+		out.Duration.From = &from
+	}
+
+	if in.DurationTo != nil {
+		to := int64(*in.DurationTo)
+		out.Duration.To = &to
+	}
+
+	if out.SortBy == "" {
+		out.SortBy = "createdAt"
+	}
+
+	if in.SortType != nil {
+		out.SortType = *in.SortType
+	}
+
+	return out
+}
+
+func GetDeadLetterTaskRequestToFilter(in dto.GetDeadLetterTaskRequest) entity.TaskFilter {
+	out := entity.TaskFilter{
+		Statuses: []entity.Status{entity.StatusDeadLetter},
+		SortBy:   "updatedAt",
+		SortType: request.SortTypeDESC,
+	}
+
+	if in.SortType != nil {
+		out.SortType = *in.SortType
+	}
+
+	return out
+}
+
 func TaskEntityToTaskDto(in entity.Task) dto.Task {
-	return dto.Task{
+	out := dto.Task{
 		Id:          in.Id,
 		Title:       in.Title,
 		Description: in.Description,
 		Status:      in.Status,
+		Type:        in.Type,
+		Payload:     in.Payload,
+		Error:       in.Error,
 		Duration:    in.Duration,
+		Priority:    in.Priority,
+		RunAt:       in.RunAt,
+		Retry:       RetryPolicyEntityToDto(in.Retry),
+		Attempts:    in.Attempts,
+		LastError:   in.LastError,
+		WorkerId:    in.WorkerId,
+		QueuedAt:    in.QueuedAt,
+		StartedAt:   in.StartedAt,
+		FinishedAt:  in.FinishedAt,
 		CreatedAt:   in.CreatedAt,
 		UpdatedAt:   in.UpdatedAt,
 	}
+
+	if wait := in.WaitTime(); wait > 0 {
+		out.WaitTime = wait.String()
+	}
+
+	if run := in.RunTime(time.Now()); run > 0 {
+		out.RunTime = run.String()
+	}
+
+	if in.Timeout > 0 {
+		out.Timeout = in.Timeout.String()
+	}
+
+	// A result is only final once the task has completed
+	if in.Status == entity.StatusCompleted {
+		out.Result = in.Result
+	}
+
+	return out
+}
+
+func RetryPolicyEntityToDto(in entity.RetryPolicy) dto.RetryPolicy {
+	out := dto.RetryPolicy{
+		MaxAttempts: in.MaxAttempts,
+		Backoff:     string(in.Backoff),
+		Jitter:      in.Jitter,
+	}
+
+	if in.Delay > 0 {
+		out.Delay = in.Delay.String()
+	}
+
+	if in.MaxDelay > 0 {
+		out.MaxDelay = in.MaxDelay.String()
+	}
+
+	return out
 }
 
 func TasksEntityToTasksDto(in []entity.Task) []dto.Task {
@@ -53,3 +247,58 @@ func TasksEntityToTasksDto(in []entity.Task) []dto.Task {
 
 	return items
 }
+
+func TaskEventsToTimeline(task entity.Task, in []entity.TaskEvent) dto.TaskTimeline {
+	out := dto.TaskTimeline{
+		TaskId: task.Id,
+		Status: task.Status,
+		Events: make([]dto.TaskEvent, 0, len(in)),
+		Total:  time.Duration(0).String(),
+	}
+
+	for i, v := range in {
+		var elapsed time.Duration
+		if i > 0 {
+			elapsed = v.CreatedAt.Sub(in[i-1].CreatedAt)
+		}
+
+		out.Events = append(out.Events, dto.TaskEvent{
+			Id:         v.Id,
+			Kind:       v.Kind(),
+			FromStatus: v.FromStatus,
+			ToStatus:   v.ToStatus,
+			WorkerId:   v.WorkerId,
+			Reason:     v.Reason,
+			At:         v.CreatedAt,
+			Elapsed:    elapsed.String(),
+		})
+	}
+
+	if len(in) > 1 {
+		out.Total = in[len(in)-1].CreatedAt.Sub(in[0].CreatedAt).String()
+	}
+
+	return out
+}
+
+func StreamTaskRequestToFilter(in dto.StreamTaskRequest) entity.EventFilter {
+	out := entity.EventFilter{}
+	for _, status := range in.Statuses {
+		out.Statuses = append(out.Statuses, entity.Status(status))
+	}
+
+	return out
+}
+
+func TaskEventToStreamEvent(in entity.TaskEvent) dto.TaskStreamEvent {
+	return dto.TaskStreamEvent{
+		Id:         in.Id,
+		TaskId:     in.TaskId,
+		Kind:       in.Kind(),
+		FromStatus: in.FromStatus,
+		ToStatus:   in.ToStatus,
+		WorkerId:   in.WorkerId,
+		Reason:     in.Reason,
+		At:         in.CreatedAt,
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/dto"
+	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/transform"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/bus"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+const (
+	// LastEventIdHeader carries the id of the last event a reconnecting client received, the stream replays
+	// the stored events which followed it
+	LastEventIdHeader = "Last-Event-ID"
+	maxReplayEvents   = 1000
+	streamHeartbeat   = 15 * time.Second
+)
+
+// MakeStream
+// @Schemes
+// @Summary Stream Task Events
+// @Description This api for a server-sent event stream of the status changes of a task. The stream starts with the recorded events of the task, or with the ones after Last-Event-ID, and ends once the task settles in a final status, the dead letter state or a timeout which is not retried
+// @Tags Task
+// @Produce text/event-stream
+// @Param id path string true "Task Id"
+// @Param Last-Event-ID header string false "Id of the last event received"
+// @Success 200  {object}  dto.TaskStreamEvent
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /tasks/{id}/stream [get]
+func (t TaskHttpApp) MakeStream() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		id, err := uuid.Parse(ginCtx.Param("id"))
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		lastEventId, err := lastEventId(ginCtx)
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		// The subscription is taken before the stored events are read so no event falls in between
+		filter := entity.EventFilter{TaskId: id}
+		sub := t.events.Subscribe(filter)
+		defer sub.Close()
+
+		taskEntity, replay, err := t.userSvc.Events(ginCtx.Request.Context(), id.String())
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		if lastEventId != "" {
+			replay, err = t.userSvc.EventsAfter(ginCtx.Request.Context(), filter, lastEventId, maxReplayEvents)
+			if err != nil {
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+
+			// The client has seen the end of the task already
+			if len(replay) == 0 && taskEntity.IsSettled() {
+				ginCtx.Status(http.StatusNoContent)
+				return
+			}
+		}
+
+		stream(ginCtx, sub, replay, settles(taskEntity))
+	}
+}
+
+// MakeStreamAll
+// @Schemes
+// @Summary Stream Events Of All Tasks
+// @Description This api for a server-sent event stream of the status changes of every task, optionally only the ones to the given statuses. A client which reconnects with Last-Event-ID gets the stored events it missed first
+// @Tags Task
+// @Produce text/event-stream
+// @Param  query query dto.StreamTaskRequest false "Statuses to stream"
+// @Param Last-Event-ID header string false "Id of the last event received"
+// @Success 200  {object}  dto.TaskStreamEvent
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /tasks/stream [get]
+func (t TaskHttpApp) MakeStreamAll() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		var req dto.StreamTaskRequest
+		if err := ginCtx.ShouldBindQuery(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := validation.BindStringSlices(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := req.Validate(ginCtx.Request.Context()); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		lastEventId, err := lastEventId(ginCtx)
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		filter := transform.StreamTaskRequestToFilter(req)
+		sub := t.events.Subscribe(filter)
+		defer sub.Close()
+
+		var replay []entity.TaskEvent
+		if lastEventId != "" {
+			replay, err = t.userSvc.EventsAfter(ginCtx.Request.Context(), filter, lastEventId, maxReplayEvents)
+			if err != nil {
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+		}
+
+		stream(ginCtx, sub, replay, nil)
+	}
+}
+
+func lastEventId(ginCtx *gin.Context) (string, error) {
+	id := ginCtx.GetHeader(LastEventIdHeader)
+	if id == "" {
+		return "", nil
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return "", &appErr.Error{
+			Cause:   err,
+			Message: fmt.Sprintf("%s must be an event id", LastEventIdHeader),
+			Class:   appErr.EBadArg,
+		}
+	}
+
+	return id, nil
+}
+
+// settles reports whether an event leaves the task settled, the stream of a task ends where a wait for it does
+func settles(task entity.Task) func(event entity.TaskEvent) bool {
+	return func(event entity.TaskEvent) bool {
+		return task.SettlesIn(event.ToStatus)
+	}
+}
+
+// stream sends the replayed events and then the live ones until the client goes away or the subscription
+// ends, and after the first event until reports when it is given. A subscription ends when the bus drops a
+// client which falls behind, the client reconnects with Last-Event-ID and catches up from the stored events.
+func stream(ginCtx *gin.Context, sub *bus.Subscription, replay []entity.TaskEvent, until func(event entity.TaskEvent) bool) {
+	header := ginCtx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	ginCtx.Status(http.StatusOK)
+	ginCtx.Writer.Flush()
+
+	sent := make(map[uuid.UUID]struct{}, len(replay))
+	for _, event := range replay {
+		if err := writeEvent(ginCtx.Writer, event); err != nil {
+			return
+		}
+		sent[event.Id] = struct{}{}
+
+		if until != nil && until(event) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ginCtx.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ginCtx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			ginCtx.Writer.Flush()
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+
+			// The replay may hold the events which were published while it was read
+			if _, ok := sent[event.Id]; ok {
+				continue
+			}
+
+			if err := writeEvent(ginCtx.Writer, event); err != nil {
+				return
+			}
+
+			if until != nil && until(event) {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w gin.ResponseWriter, event entity.TaskEvent) error {
+	data, err := json.Marshal(transform.TaskEventToStreamEvent(event))
+	if err != nil {
+		return err
+	}
+
+	if _, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Kind(), data); err != nil {
+		return err
+	}
+	w.Flush()
+
+	return nil
+}
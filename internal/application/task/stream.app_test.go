@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/dto"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/bus"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+)
+
+func newStreamContext(t *testing.T) (*gin.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	t.Cleanup(cancel)
+
+	recorder := httptest.NewRecorder()
+	ginCtx, _ := gin.CreateTestContext(recorder)
+	ginCtx.Request = httptest.NewRequest(http.MethodGet, "/tasks/stream", nil).WithContext(ctx)
+	return ginCtx, recorder
+}
+
+func newStreamEvent(taskId uuid.UUID, from, to entity.Status) entity.TaskEvent {
+	return entity.TaskEvent{Id: uuid.New(), TaskId: taskId, FromStatus: from, ToStatus: to}
+}
+
+// streamedStatuses returns the statuses the events written to the stream move their task to, in order
+func streamedStatuses(t *testing.T, body string) []entity.Status {
+	t.Helper()
+
+	var statuses []entity.Status
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+
+		var event dto.TaskStreamEvent
+		require.NoError(t, json.Unmarshal([]byte(data), &event))
+		statuses = append(statuses, event.ToStatus)
+	}
+	return statuses
+}
+
+// retried is a task which is attempted twice before it is dead lettered
+var retried = entity.Task{Status: entity.StatusPending, Retry: entity.RetryPolicy{MaxAttempts: 2}}
+
+func TestStream_OutlivesTimeoutAndRetry(t *testing.T) {
+	events := bus.New(16)
+	defer events.Close()
+
+	taskId := uuid.New()
+	sub := events.Subscribe(entity.EventFilter{TaskId: taskId})
+	defer sub.Close()
+
+	events.Publish(newStreamEvent(taskId, entity.StatusPending, entity.StatusRunning))
+	events.Publish(newStreamEvent(taskId, entity.StatusRunning, entity.StatusTimedOut))
+	events.Publish(newStreamEvent(taskId, entity.StatusTimedOut, entity.StatusPending))
+	events.Publish(newStreamEvent(taskId, entity.StatusPending, entity.StatusRunning))
+	events.Publish(newStreamEvent(taskId, entity.StatusRunning, entity.StatusCompleted))
+
+	ginCtx, recorder := newStreamContext(t)
+	stream(ginCtx, sub, nil, settles(retried))
+
+	require.NoError(t, ginCtx.Request.Context().Err(), "the stream must end on the completed event")
+	assert.Equal(t, []entity.Status{
+		entity.StatusRunning,
+		entity.StatusTimedOut,
+		entity.StatusPending,
+		entity.StatusRunning,
+		entity.StatusCompleted,
+	}, streamedStatuses(t, recorder.Body.String()))
+}
+
+func TestStream_ReplayedTimeoutKeepsStreaming(t *testing.T) {
+	events := bus.New(16)
+	defer events.Close()
+
+	taskId := uuid.New()
+	sub := events.Subscribe(entity.EventFilter{TaskId: taskId})
+	defer sub.Close()
+
+	replay := []entity.TaskEvent{
+		newStreamEvent(taskId, entity.StatusPending, entity.StatusRunning),
+		newStreamEvent(taskId, entity.StatusRunning, entity.StatusTimedOut),
+	}
+	events.Publish(replay[1])
+	events.Publish(newStreamEvent(taskId, entity.StatusTimedOut, entity.StatusPending))
+	events.Publish(newStreamEvent(taskId, entity.StatusPending, entity.StatusRunning))
+	events.Publish(newStreamEvent(taskId, entity.StatusRunning, entity.StatusCompleted))
+
+	ginCtx, recorder := newStreamContext(t)
+	stream(ginCtx, sub, replay, settles(retried))
+
+	require.NoError(t, ginCtx.Request.Context().Err(), "the stream must end on the completed event")
+	assert.Equal(t, []entity.Status{
+		entity.StatusRunning,
+		entity.StatusTimedOut,
+		entity.StatusPending,
+		entity.StatusRunning,
+		entity.StatusCompleted,
+	}, streamedStatuses(t, recorder.Body.String()))
+}
+
+func TestStream_EndsOnDeadLetter(t *testing.T) {
+	events := bus.New(16)
+	defer events.Close()
+
+	taskId := uuid.New()
+	sub := events.Subscribe(entity.EventFilter{TaskId: taskId})
+	defer sub.Close()
+
+	events.Publish(newStreamEvent(taskId, entity.StatusPending, entity.StatusRunning))
+	events.Publish(newStreamEvent(taskId, entity.StatusRunning, entity.StatusTimedOut))
+	events.Publish(newStreamEvent(taskId, entity.StatusTimedOut, entity.StatusPending))
+	events.Publish(newStreamEvent(taskId, entity.StatusPending, entity.StatusRunning))
+	events.Publish(newStreamEvent(taskId, entity.StatusRunning, entity.StatusDeadLetter))
+
+	ginCtx, recorder := newStreamContext(t)
+	stream(ginCtx, sub, nil, settles(retried))
+
+	require.NoError(t, ginCtx.Request.Context().Err(), "the stream must end on the dead letter event")
+	assert.Equal(t, []entity.Status{
+		entity.StatusRunning,
+		entity.StatusTimedOut,
+		entity.StatusPending,
+		entity.StatusRunning,
+		entity.StatusDeadLetter,
+	}, streamedStatuses(t, recorder.Body.String()))
+}
+
+func TestStream_EndsOnTimeoutWithoutRetry(t *testing.T) {
+	events := bus.New(16)
+	defer events.Close()
+
+	taskId := uuid.New()
+	sub := events.Subscribe(entity.EventFilter{TaskId: taskId})
+	defer sub.Close()
+
+	events.Publish(newStreamEvent(taskId, entity.StatusPending, entity.StatusRunning))
+	events.Publish(newStreamEvent(taskId, entity.StatusRunning, entity.StatusTimedOut))
+
+	ginCtx, recorder := newStreamContext(t)
+	stream(ginCtx, sub, nil, settles(entity.Task{Status: entity.StatusPending}))
+
+	require.NoError(t, ginCtx.Request.Context().Err(), "the stream must end on a timeout which is not retried")
+	assert.Equal(t, []entity.Status{
+		entity.StatusRunning,
+		entity.StatusTimedOut,
+	}, streamedStatuses(t, recorder.Body.String()))
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/dto"
+	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/transform"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/bus"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+// subscriber serves a single WebSocket: read takes the requests of the client and hands the subscription to the
+// bus, forward passes on the events the bus picked and write is the only one which writes to the socket. Every
+// message goes through the bounded send buffer, the connection is dropped once the buffer is full.
+type subscriber struct {
+	conn   *websocket.Conn
+	events *bus.Subscription
+	send   chan []byte
+	limits subscriberLimits
+
+	done      chan struct{}
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+
+	mu       sync.Mutex
+	ids      map[uuid.UUID]struct{}
+	statuses map[entity.Status]struct{}
+}
+
+func newSubscriber(conn *websocket.Conn, events *bus.Subscription, limits subscriberLimits) *subscriber {
+	return &subscriber{
+		conn:     conn,
+		events:   events,
+		send:     make(chan []byte, limits.buffer),
+		limits:   limits,
+		done:     make(chan struct{}),
+		ids:      make(map[uuid.UUID]struct{}),
+		statuses: make(map[entity.Status]struct{}),
+	}
+}
+
+// run serves the connection until the client leaves, the event bus ends the subscription or the client falls
+// behind
+func (s *subscriber) run(ctx context.Context) {
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		s.write()
+	}()
+	go s.forward()
+
+	s.read(ctx)
+	s.close(websocket.CloseNormalClosure, "")
+	<-written
+}
+
+func (s *subscriber) read(ctx context.Context) {
+	s.conn.SetReadLimit(maxRequestSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(s.limits.pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(s.limits.pongWait))
+	})
+
+	for {
+		_, message, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req dto.SubscriptionRequest
+		if err = json.Unmarshal(message, &req); err == nil {
+			err = req.Validate(ctx)
+		}
+
+		reply := dto.SubscriptionReply{Type: dto.SubscriptionReplyError}
+		if err == nil {
+			reply, err = s.apply(req)
+		}
+		if err != nil {
+			reply.Error = err.Error()
+			var causes validation.ErrValidation
+			if errors.As(err, &causes) {
+				reply.Causes = causes
+			}
+		}
+
+		if !s.enqueue(reply) {
+			return
+		}
+	}
+}
+
+// subscriptionFilter picks the events of the subscribed tasks and the events to the subscribed statuses, it is
+// rebuilt on every change as the bus may read it at any time
+type subscriptionFilter struct {
+	ids      map[uuid.UUID]struct{}
+	statuses map[entity.Status]struct{}
+}
+
+func (f subscriptionFilter) Matches(event entity.TaskEvent) bool {
+	if _, ok := f.ids[event.TaskId]; ok {
+		return true
+	}
+	_, ok := f.statuses[event.ToStatus]
+	return ok
+}
+
+// apply changes the subscription, hands it to the bus and returns the whole of it
+func (s *subscriber) apply(req dto.SubscriptionRequest) (dto.SubscriptionReply, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.Action {
+	case dto.SubscriptionActionSubscribe:
+		added := 0
+		for _, id := range req.Ids {
+			if _, ok := s.ids[uuid.MustParse(id)]; !ok {
+				added++
+			}
+		}
+		if len(s.ids)+added > maxSubscribedIds {
+			return dto.SubscriptionReply{Type: dto.SubscriptionReplyError}, fmt.Errorf("a connection can subscribe to up to %d tasks", maxSubscribedIds)
+		}
+
+		for _, id := range req.Ids {
+			s.ids[uuid.MustParse(id)] = struct{}{}
+		}
+		for _, status := range req.Statuses {
+			s.statuses[entity.Status(status)] = struct{}{}
+		}
+	case dto.SubscriptionActionUnsubscribe:
+		for _, id := range req.Ids {
+			delete(s.ids, uuid.MustParse(id))
+		}
+		for _, status := range req.Statuses {
+			delete(s.statuses, entity.Status(status))
+		}
+	}
+
+	reply := dto.SubscriptionReply{Type: dto.SubscriptionReplySubscribed}
+	for id := range s.ids {
+		reply.Ids = append(reply.Ids, id.String())
+	}
+	for status := range s.statuses {
+		reply.Statuses = append(reply.Statuses, string(status))
+	}
+	slices.Sort(reply.Ids)
+	slices.Sort(reply.Statuses)
+
+	s.events.SetFilter(subscriptionFilter{ids: maps.Clone(s.ids), statuses: maps.Clone(s.statuses)})
+
+	return reply, nil
+}
+
+func (s *subscriber) forward() {
+	defer s.events.Close()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case event, ok := <-s.events.Events():
+			if !ok {
+				if errors.Is(s.events.Err(), bus.ErrLagging) {
+					s.close(websocket.ClosePolicyViolation, "fell behind the events")
+				} else {
+					s.close(websocket.CloseGoingAway, "server is shutting down")
+				}
+				return
+			}
+
+			streamEvent := transform.TaskEventToStreamEvent(event)
+			if !s.enqueue(dto.SubscriptionReply{Type: dto.SubscriptionReplyEvent, Event: &streamEvent}) {
+				return
+			}
+		}
+	}
+}
+
+// enqueue hands the reply to the writer without waiting, it drops the connection once the send buffer is full
+func (s *subscriber) enqueue(reply dto.SubscriptionReply) bool {
+	message, err := json.Marshal(reply)
+	if err != nil {
+		log.Printf("[SUBSCRIBER] cannot encode a %s reply: %v", reply.Type, err)
+		return true
+	}
+
+	select {
+	case <-s.done:
+		return false
+	case s.send <- message:
+		return true
+	default:
+		log.Printf("[SUBSCRIBER] dropping %s which fell %d messages behind", s.conn.RemoteAddr(), cap(s.send))
+		s.close(websocket.ClosePolicyViolation, "send buffer is full")
+		return false
+	}
+}
+
+func (s *subscriber) write() {
+	ping := time.NewTicker(s.limits.ping)
+	defer ping.Stop()
+	defer s.conn.Close()
+
+	for {
+		select {
+		case <-s.done:
+			message := websocket.FormatCloseMessage(s.closeCode, s.closeText)
+			_ = s.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(s.limits.write))
+			return
+		case message := <-s.send:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(s.limits.write))
+			if err := s.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ping.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(s.limits.write)); err != nil {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+// close ends the connection with the given close code, only the first call counts
+func (s *subscriber) close(code int, text string) {
+	s.closeOnce.Do(func() {
+		s.closeCode = code
+		s.closeText = text
+		close(s.done)
+	})
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/bus"
+)
+
+const (
+	// subscriberBuffer is the number of messages a connection may fall behind before it is dropped
+	subscriberBuffer   = 64
+	maxSubscribedIds   = 1000
+	maxRequestSize     = 64 << 10
+	subscriberPongWait = 60 * time.Second
+	subscriberPing     = subscriberPongWait * 9 / 10
+	subscriberWrite    = 10 * time.Second
+)
+
+// subscriberLimits bound every connection: the messages it may fall behind, the wait for its pong and
+// the time a write to it may take
+type subscriberLimits struct {
+	buffer   int
+	pongWait time.Duration
+	ping     time.Duration
+	write    time.Duration
+}
+
+type TaskSubscriptionApp struct {
+	events   *bus.Bus
+	upgrader *websocket.Upgrader
+	limits   subscriberLimits
+}
+
+func NewTaskSubscriptionApp(events *bus.Bus) TaskSubscriptionApp {
+	return TaskSubscriptionApp{
+		events: events,
+		upgrader: &websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+		limits: subscriberLimits{
+			buffer:   subscriberBuffer,
+			pongWait: subscriberPongWait,
+			ping:     subscriberPing,
+			write:    subscriberWrite,
+		},
+	}
+}
+
+// MakeSubscribe
+// @Schemes
+// @Summary Subscribe To Task Events
+// @Description This api for a WebSocket which pushes the status changes of the subscribed tasks. The client sends dto.SubscriptionRequest messages to subscribe to or unsubscribe from task ids and statuses and gets the whole subscription back, then every matching event as a dto.SubscriptionReply. The server pings every 54s and drops a connection which does not answer within 60s or falls 64 messages behind.
+// @Tags Task
+// @Param  body body dto.SubscriptionRequest false "Message sent over the socket"
+// @Success 101  {object}  dto.SubscriptionReply
+// @Router /tasks/subscribe [get]
+func (t TaskSubscriptionApp) MakeSubscribe() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		// The upgrader answers the failed handshakes itself
+		conn, err := t.upgrader.Upgrade(ginCtx.Writer, ginCtx.Request, nil)
+		if err != nil {
+			return
+		}
+
+		newSubscriber(conn, t.events.Subscribe(subscriptionFilter{}), t.limits).run(ginCtx.Request.Context())
+	}
+}
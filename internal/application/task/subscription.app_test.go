@@ -0,0 +1,275 @@
+package service
+
+import (
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/dto"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/bus"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+)
+
+// smallBuffers keeps the socket buffers of the accepted connections small so a client which does not read
+// blocks the writes of the server soon
+type smallBuffers struct {
+	net.Listener
+}
+
+func (l smallBuffers) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetWriteBuffer(4096)
+	}
+	return conn, err
+}
+
+// newSubscriptionServer serves the subscription of the app, done is closed once the handler returns
+func newSubscriptionServer(t *testing.T, app TaskSubscriptionApp) (url string, done <-chan struct{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	served := make(chan struct{})
+	router := gin.New()
+	router.GET("/tasks/subscribe", func(ginCtx *gin.Context) {
+		defer close(served)
+		app.MakeSubscribe()(ginCtx)
+	})
+
+	server := httptest.NewUnstartedServer(router)
+	server.Listener = smallBuffers{Listener: server.Listener}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/tasks/subscribe", served
+}
+
+func dialSubscription(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+
+	dialer := *websocket.DefaultDialer
+	dialer.NetDial = func(network, addr string) (net.Conn, error) {
+		conn, err := net.Dial(network, addr)
+		if tcp, ok := conn.(*net.TCPConn); ok {
+			_ = tcp.SetReadBuffer(4096)
+		}
+		return conn, err
+	}
+
+	conn, _, err := dialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// readReplies reads the replies of the server until the connection ends, the channel is closed then
+func readReplies(conn *websocket.Conn) <-chan dto.SubscriptionReply {
+	replies := make(chan dto.SubscriptionReply, 64)
+	go func() {
+		defer close(replies)
+		for {
+			var reply dto.SubscriptionReply
+			if err := conn.ReadJSON(&reply); err != nil {
+				return
+			}
+			replies <- reply
+		}
+	}()
+	return replies
+}
+
+func nextReply(t *testing.T, replies <-chan dto.SubscriptionReply) dto.SubscriptionReply {
+	t.Helper()
+
+	select {
+	case reply, ok := <-replies:
+		require.True(t, ok, "the connection ended")
+		return reply
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "no reply from the server")
+		return dto.SubscriptionReply{}
+	}
+}
+
+func request(t *testing.T, conn *websocket.Conn, req dto.SubscriptionRequest, replies <-chan dto.SubscriptionReply) dto.SubscriptionReply {
+	t.Helper()
+
+	require.NoError(t, conn.WriteJSON(req))
+	return nextReply(t, replies)
+}
+
+func waitServed(t *testing.T, done <-chan struct{}, within time.Duration) {
+	t.Helper()
+
+	select {
+	case <-done:
+	case <-time.After(within):
+		require.FailNow(t, "the server kept the connection")
+	}
+}
+
+func TestSubscribe_SubscriptionPicksEvents(t *testing.T) {
+	events := bus.New(16)
+	defer events.Close()
+
+	url, _ := newSubscriptionServer(t, NewTaskSubscriptionApp(events))
+	conn := dialSubscription(t, url)
+	replies := readReplies(conn)
+
+	first, second := uuid.New(), uuid.New()
+	reply := request(t, conn, dto.SubscriptionRequest{Action: dto.SubscriptionActionSubscribe, Ids: []string{first.String()}}, replies)
+	require.Equal(t, dto.SubscriptionReplySubscribed, reply.Type)
+	assert.Equal(t, []string{first.String()}, reply.Ids)
+
+	events.Publish(newStreamEvent(second, entity.StatusPending, entity.StatusRunning))
+	events.Publish(newStreamEvent(first, entity.StatusPending, entity.StatusRunning))
+
+	reply = nextReply(t, replies)
+	require.Equal(t, dto.SubscriptionReplyEvent, reply.Type)
+	assert.Equal(t, first, reply.Event.TaskId, "only the subscribed task is sent")
+
+	reply = request(t, conn, dto.SubscriptionRequest{Action: dto.SubscriptionActionUnsubscribe, Ids: []string{first.String()}}, replies)
+	require.Equal(t, dto.SubscriptionReplySubscribed, reply.Type)
+	assert.Empty(t, reply.Ids)
+
+	reply = request(t, conn, dto.SubscriptionRequest{Action: dto.SubscriptionActionSubscribe, Statuses: []string{string(entity.StatusCompleted)}}, replies)
+	require.Equal(t, dto.SubscriptionReplySubscribed, reply.Type)
+	assert.Equal(t, []string{string(entity.StatusCompleted)}, reply.Statuses)
+
+	events.Publish(newStreamEvent(first, entity.StatusRunning, entity.StatusCompleted))
+	events.Publish(newStreamEvent(second, entity.StatusRunning, entity.StatusFailed))
+	events.Publish(newStreamEvent(second, entity.StatusFailed, entity.StatusCompleted))
+
+	reply = nextReply(t, replies)
+	require.Equal(t, dto.SubscriptionReplyEvent, reply.Type)
+	assert.Equal(t, first, reply.Event.TaskId)
+	assert.Equal(t, entity.StatusCompleted, reply.Event.ToStatus)
+
+	reply = nextReply(t, replies)
+	require.Equal(t, dto.SubscriptionReplyEvent, reply.Type)
+	assert.Equal(t, second, reply.Event.TaskId, "the failed event of the unsubscribed task is not sent")
+	assert.Equal(t, entity.StatusCompleted, reply.Event.ToStatus)
+}
+
+func TestSubscribe_CapsSubscribedIds(t *testing.T) {
+	events := bus.New(16)
+	defer events.Close()
+
+	url, _ := newSubscriptionServer(t, NewTaskSubscriptionApp(events))
+	conn := dialSubscription(t, url)
+	replies := readReplies(conn)
+
+	ids := make([]string, maxSubscribedIds)
+	for i := range ids {
+		ids[i] = uuid.NewString()
+	}
+
+	reply := request(t, conn, dto.SubscriptionRequest{Action: dto.SubscriptionActionSubscribe, Ids: ids}, replies)
+	require.Equal(t, dto.SubscriptionReplySubscribed, reply.Type)
+	assert.Len(t, reply.Ids, maxSubscribedIds)
+
+	reply = request(t, conn, dto.SubscriptionRequest{Action: dto.SubscriptionActionSubscribe, Ids: []string{uuid.NewString()}}, replies)
+	assert.Equal(t, dto.SubscriptionReplyError, reply.Type)
+	assert.Contains(t, reply.Error, "up to 1000 tasks")
+
+	reply = request(t, conn, dto.SubscriptionRequest{Action: dto.SubscriptionActionSubscribe, Ids: ids[:1]}, replies)
+	require.Equal(t, dto.SubscriptionReplySubscribed, reply.Type, "an id subscribed already does not count again")
+	assert.Len(t, reply.Ids, maxSubscribedIds)
+}
+
+func TestSubscribe_DropsConsumerWhichDoesNotRead(t *testing.T) {
+	events := bus.New(16)
+	defer events.Close()
+
+	app := NewTaskSubscriptionApp(events)
+	app.limits.buffer = 4
+	app.limits.write = 100 * time.Millisecond
+
+	url, done := newSubscriptionServer(t, app)
+	conn := dialSubscription(t, url)
+
+	require.NoError(t, conn.WriteJSON(dto.SubscriptionRequest{
+		Action:   dto.SubscriptionActionSubscribe,
+		Statuses: []string{string(entity.StatusRunning)},
+	}))
+
+	// The client never reads, every publish must still return at once until the server drops the connection
+	deadline := time.After(5 * time.Second)
+	for dropped := false; !dropped; {
+		select {
+		case <-done:
+			dropped = true
+		case <-deadline:
+			require.FailNow(t, "the consumer which does not read was not dropped")
+		default:
+			published := make(chan struct{})
+			go func() {
+				defer close(published)
+				events.Publish(newStreamEvent(uuid.New(), entity.StatusPending, entity.StatusRunning))
+			}()
+			select {
+			case <-published:
+			case <-time.After(time.Second):
+				require.FailNow(t, "the publisher is blocked by the consumer")
+			}
+		}
+	}
+
+	// The client reads what was buffered before the drop and then the end of the connection
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			var netErr net.Error
+			assert.False(t, errors.As(err, &netErr) && netErr.Timeout(), "the connection must be closed: %v", err)
+			return
+		}
+	}
+}
+
+func TestSubscribe_KeepsConsumerWhichAnswersPings(t *testing.T) {
+	events := bus.New(16)
+	defer events.Close()
+
+	app := NewTaskSubscriptionApp(events)
+	app.limits.pongWait = 200 * time.Millisecond
+	app.limits.ping = 100 * time.Millisecond
+
+	url, done := newSubscriptionServer(t, app)
+	conn := dialSubscription(t, url)
+	replies := readReplies(conn)
+
+	select {
+	case <-done:
+		require.FailNow(t, "the server dropped a consumer which answers its pings")
+	case <-time.After(3 * app.limits.pongWait):
+	}
+
+	reply := request(t, conn, dto.SubscriptionRequest{Action: dto.SubscriptionActionSubscribe, Statuses: []string{string(entity.StatusRunning)}}, replies)
+	assert.Equal(t, dto.SubscriptionReplySubscribed, reply.Type)
+}
+
+func TestSubscribe_DropsConsumerWhichDoesNotAnswerPings(t *testing.T) {
+	events := bus.New(16)
+	defer events.Close()
+
+	app := NewTaskSubscriptionApp(events)
+	app.limits.pongWait = 200 * time.Millisecond
+	app.limits.ping = 100 * time.Millisecond
+
+	url, done := newSubscriptionServer(t, app)
+	conn := dialSubscription(t, url)
+	conn.SetPingHandler(func(string) error { return nil })
+	replies := readReplies(conn)
+
+	waitServed(t, done, time.Second)
+	for range replies {
+	}
+}
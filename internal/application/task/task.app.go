@@ -1,42 +1,77 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/dto"
 	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/transform"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/bus"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/notifier"
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/pool"
 	userInterface "github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
 	"github.com/thealiakbari/task-pool-system/pkg/common/db"
 	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+	"github.com/thealiakbari/task-pool-system/pkg/common/utiles"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+const (
+	// IdempotencyKeyHeader lets a client retry a create request without creating the task twice
+	IdempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+	defaultWaitTimeout   = 30 * time.Second
 )
 
 type TaskHttpApp struct {
 	userSvc          userInterface.TaskService
 	poolWorkerHelper *pool.Pool
+	events           *bus.Bus
+	completions      *notifier.Notifier
 	db               db.DBWrapper
 }
 
-func NewTaskHttpApp(userSvc userInterface.TaskService, db db.DBWrapper, poolWorkerHelper *pool.Pool) TaskHttpApp {
+func NewTaskHttpApp(
+	userSvc userInterface.TaskService,
+	db db.DBWrapper,
+	poolWorkerHelper *pool.Pool,
+	events *bus.Bus,
+	completions *notifier.Notifier,
+) TaskHttpApp {
 	return TaskHttpApp{
 		db:               db,
 		userSvc:          userSvc,
 		poolWorkerHelper: poolWorkerHelper,
+		events:           events,
+		completions:      completions,
 	}
 }
 
 // MakeCreate
 // @Schemes
 // @Summary Create Task
-// @Description This api for create task
+// @Description This api for create task, a request sent again with the same Idempotency-Key gets the response of the first one
 // @Tags Task
 // @Accept json
 // @Produce json
 // @Content-Type application/json
+// @Param  Idempotency-Key header string false "Unique key of the request, up to 255 characters"
 // @Param  body body dto.CreateTaskRequest true "Contains information to set data"
 // @Success 201  {object}  dto.Task
 // @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 409  {object}  appErr.ErrSwaggerResponse
 // @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 429  {object}  appErr.ErrSwaggerResponse
 // @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Failure 503  {object}  appErr.ErrSwaggerResponse
 // @Router /tasks [post]
 func (t TaskHttpApp) MakeCreate() gin.HandlerFunc {
 	return func(ginCtx *gin.Context) {
@@ -59,6 +94,58 @@ func (t TaskHttpApp) MakeCreate() gin.HandlerFunc {
 			return
 		}
 
+		createReq, err := transform.CreateTaskRequestToEntity(req)
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		idempotencyKey := ginCtx.GetHeader(IdempotencyKeyHeader)
+		if len(idempotencyKey) > maxIdempotencyKeyLen {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   errors.New("idempotency key is too long"),
+				Message: fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLen),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		requestHash, err := req.Hash()
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		if idempotencyKey != "" {
+			stored, err := t.userSvc.FindIdempotencyKey(ginCtx.Request.Context(), idempotencyKey)
+			if err != nil {
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+
+			if stored.Key != "" {
+				if !stored.Matches(requestHash) {
+					appErr.HandelError(ginCtx, entity.ErrIdempotencyKeyReused)
+					return
+				}
+
+				appErr.StatusResponse(ginCtx, stored.StatusCode, stored.Response)
+				return
+			}
+		}
+
+		// Scheduled tasks are handed to the pool once they are due, only the tasks which run now need room
+		if createReq.Status == entity.StatusPending {
+			if err := t.admit(ginCtx.Request.Context()); err != nil {
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+		}
+
 		tx, ctx, err := db.BeginTx(ginCtx.Request.Context(), t.db.DB)
 		if err != nil {
 			appErr.HandelError(ginCtx, &appErr.Error{
@@ -84,19 +171,26 @@ func (t TaskHttpApp) MakeCreate() gin.HandlerFunc {
 			}
 		}()
 
-		pollEntityResp, err := t.userSvc.Create(ctx, transform.CreateTaskRequestToEntity(req))
+		pollEntityResp, err := t.userSvc.Create(ctx, createReq)
 		if err != nil {
-			appErr.HandelError(ginCtx, err)
 			return
 		}
 
-		err = t.poolWorkerHelper.Submit(&pollEntityResp)
-		if err != nil {
-			appErr.HandelError(ginCtx, err)
-			return
+		taskDto := transform.TaskEntityToTaskDto(pollEntityResp)
+		if idempotencyKey != "" {
+			response, marshalErr := json.Marshal(taskDto)
+			if marshalErr != nil {
+				err = marshalErr
+				return
+			}
+
+			err = t.userSvc.SaveIdempotencyKey(ctx, entity.NewIdempotencyKey(idempotencyKey, requestHash, pollEntityResp.Id, http.StatusCreated, response))
+			if err != nil {
+				return
+			}
 		}
 
-		if err = tx.Commit().Error; err != nil {
+		if err = db.Commit(ctx, tx); err != nil {
 			appErr.HandelError(ginCtx, &appErr.Error{
 				Cause:   err,
 				Message: err.Error(),
@@ -105,7 +199,15 @@ func (t TaskHttpApp) MakeCreate() gin.HandlerFunc {
 			return
 		}
 
-		appErr.CreatedResponse(ginCtx, transform.TaskEntityToTaskDto(pollEntityResp))
+		// The task is durable once committed, when the queue filled up since it was admitted the pool
+		// claims it later
+		if pollEntityResp.Status == entity.StatusPending {
+			if err := t.poolWorkerHelper.Submit(&pollEntityResp); err != nil {
+				log.Printf("[TASK] task %s is left to the durable backlog: %v", pollEntityResp.Id, err)
+			}
+		}
+
+		appErr.CreatedResponse(ginCtx, taskDto)
 	}
 }
 
@@ -121,6 +223,7 @@ func (t TaskHttpApp) MakeCreate() gin.HandlerFunc {
 // @Param  body body dto.UpdateTaskRequest true "Contains information to set data"
 // @Success 200  {object}  dto.Task
 // @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
 // @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
 // @Failure 500  {object}  appErr.ErrSwaggerResponse
 // @Router /tasks/{id} [put]
@@ -161,22 +264,157 @@ func (t TaskHttpApp) MakeUpdate() gin.HandlerFunc {
 			}
 		}()
 
-		updateReq, err := transform.UpdateTaskRequestToEntity(req, ginCtx.Param("id"))
+		if _, err = uuid.Parse(ginCtx.Param("id")); err != nil {
+			err = &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			}
+			return
+		}
+
+		current, err := t.userSvc.GetByIdOrEmpty(ctx, ginCtx.Param("id"))
 		if err != nil {
+			return
+		}
+
+		pollEntityResp, err := t.userSvc.Update(ctx, transform.UpdateTaskRequestToEntity(req, current))
+		if err != nil {
+			return
+		}
+
+		if err = db.Commit(ctx, tx); err != nil {
 			appErr.HandelError(ginCtx, &appErr.Error{
 				Cause:   err,
 				Message: err.Error(),
-				Class:   appErr.EBadArg,
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		appErr.OKResponse(ginCtx, transform.TaskEntityToTaskDto(pollEntityResp))
+	}
+}
+
+// MakeCancel
+// @Schemes
+// @Summary Cancel Task
+// @Description This api for cancel a pending or running task
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param id path string true "Task Id"
+// @Success 200  {object}  dto.Task
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
+// @Failure 409  {object}  appErr.ErrSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /tasks/{id}/cancel [post]
+func (t TaskHttpApp) MakeCancel() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		tx, ctx, err := db.BeginTx(ginCtx.Request.Context(), t.db.DB)
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
 			})
 			return
 		}
-		pollEntityResp, err := t.userSvc.Update(ctx, updateReq)
+
+		defer func() {
+			if err != nil {
+				if err := tx.Rollback().Error; err != nil {
+					appErr.HandelError(ginCtx, &appErr.Error{
+						Cause:   err,
+						Message: err.Error(),
+						Class:   appErr.EConflict,
+					})
+					return
+				}
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+		}()
+
+		pollEntityResp, err := t.userSvc.Cancel(ctx, ginCtx.Param("id"))
 		if err != nil {
+			return
+		}
+
+		if err = db.Commit(ctx, tx); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		// The pool of this executor stops the task once the cancel is stored, the pool holding it elsewhere
+		// finds the stored status when it renews its leases
+		t.poolWorkerHelper.Cancel(pollEntityResp.Id)
+
+		appErr.OKResponse(ginCtx, transform.TaskEntityToTaskDto(pollEntityResp))
+	}
+}
+
+// MakeRedrive
+// @Schemes
+// @Summary Re-drive Dead Letter Task
+// @Description This api for moving a dead lettered task back to the pool with a fresh attempt budget
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param id path string true "Task Id"
+// @Success 200  {object}  dto.Task
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
+// @Failure 409  {object}  appErr.ErrSwaggerResponse
+// @Failure 429  {object}  appErr.ErrSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Failure 503  {object}  appErr.ErrSwaggerResponse
+// @Router /tasks/{id}/redrive [post]
+func (t TaskHttpApp) MakeRedrive() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		if err := t.admit(ginCtx.Request.Context()); err != nil {
 			appErr.HandelError(ginCtx, err)
 			return
 		}
 
-		if err = tx.Commit().Error; err != nil {
+		tx, ctx, err := db.BeginTx(ginCtx.Request.Context(), t.db.DB)
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EConflict,
+			})
+			return
+		}
+
+		defer func() {
+			if err != nil {
+				if err := tx.Rollback().Error; err != nil {
+					appErr.HandelError(ginCtx, &appErr.Error{
+						Cause:   err,
+						Message: err.Error(),
+						Class:   appErr.EConflict,
+					})
+					return
+				}
+				appErr.HandelError(ginCtx, err)
+				return
+			}
+		}()
+
+		pollEntityResp, err := t.userSvc.Redrive(ctx, ginCtx.Param("id"))
+		if err != nil {
+			return
+		}
+
+		if err = db.Commit(ctx, tx); err != nil {
 			appErr.HandelError(ginCtx, &appErr.Error{
 				Cause:   err,
 				Message: err.Error(),
@@ -185,6 +423,10 @@ func (t TaskHttpApp) MakeUpdate() gin.HandlerFunc {
 			return
 		}
 
+		if err := t.poolWorkerHelper.Submit(&pollEntityResp); err != nil {
+			log.Printf("[TASK] task %s is left to the durable backlog: %v", pollEntityResp.Id, err)
+		}
+
 		appErr.OKResponse(ginCtx, transform.TaskEntityToTaskDto(pollEntityResp))
 	}
 }
@@ -236,7 +478,7 @@ func (t TaskHttpApp) MakeDelete() gin.HandlerFunc {
 			return
 		}
 
-		if err = tx.Commit().Error; err != nil {
+		if err = db.Commit(ctx, tx); err != nil {
 			appErr.HandelError(ginCtx, &appErr.Error{
 				Cause:   err,
 				Message: err.Error(),
@@ -294,7 +536,7 @@ func (t TaskHttpApp) MakePurge() gin.HandlerFunc {
 			return
 		}
 
-		if err = tx.Commit().Error; err != nil {
+		if err = db.Commit(ctx, tx); err != nil {
 			appErr.HandelError(ginCtx, &appErr.Error{
 				Cause:   err,
 				Message: err.Error(),
@@ -310,7 +552,7 @@ func (t TaskHttpApp) MakePurge() gin.HandlerFunc {
 // MakeGetById
 // @Schemes
 // @Summary Get Task By Id
-// @Description This api for task by id
+// @Description This api for task by id, the result of the task is returned once it is completed
 // @Tags Task
 // @Accept json
 // @Produce json
@@ -332,3 +574,308 @@ func (t TaskHttpApp) MakeGetById() gin.HandlerFunc {
 		appErr.OKResponse(ginCtx, transform.TaskEntityToTaskDto(pollEntityResp))
 	}
 }
+
+// MakeWait
+// @Schemes
+// @Summary Wait For Task
+// @Description This api for waiting until a task settles in a final, dead letter or not retried timed out status, the current state of the task is returned once it does or once the timeout expires
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param id path string true "Task Id"
+// @Param  query query dto.WaitTaskRequest false "Time to wait, 30s by default"
+// @Success 200  {object} dto.Task
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
+// @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /tasks/{id}/wait [get]
+func (t TaskHttpApp) MakeWait() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		id, err := uuid.Parse(ginCtx.Param("id"))
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		var req dto.WaitTaskRequest
+		if err = ginCtx.ShouldBindQuery(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err = req.Validate(ginCtx.Request.Context()); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		timeout := defaultWaitTimeout
+		if req.Timeout != nil {
+			timeout = *req.Timeout
+		}
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		taskEntity, err := t.waitSettled(ginCtx.Request.Context(), id, timer.C)
+		if ginCtx.Request.Context().Err() != nil {
+			return
+		}
+
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		if taskEntity.Id == uuid.Nil {
+			appErr.NotFoundResponse(ginCtx)
+			return
+		}
+
+		appErr.OKResponse(ginCtx, transform.TaskEntityToTaskDto(taskEntity))
+	}
+}
+
+// waitSettled returns the task once it settles, or as it is when expired fires or the notifier is closed. The
+// task is read when the wait starts and once more when it ends, in between the notifier tells its changes.
+func (t TaskHttpApp) waitSettled(ctx context.Context, id uuid.UUID, expired <-chan time.Time) (entity.Task, error) {
+	// The watch is taken before the task is read so a change can not fall in between
+	watch := t.completions.Watch(id)
+	defer watch.Stop()
+
+	res, err := t.userSvc.GetByIdOrEmpty(ctx, id.String())
+	if err != nil || res.Id == uuid.Nil || res.IsSettled() {
+		return res, err
+	}
+
+	for {
+		changed, last, err := watch.Next()
+		if err != nil || (last != "" && res.SettlesIn(last)) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return entity.Task{}, ctx.Err()
+		case <-expired:
+			// The wait ran out, the task is answered as it is now
+			return t.userSvc.GetByIdOrEmpty(ctx, id.String())
+		case <-changed:
+		}
+	}
+
+	// The task settled or the executor shuts down
+	return t.userSvc.GetByIdOrEmpty(ctx, id.String())
+}
+
+// MakeGetEvents
+// @Schemes
+// @Summary Get Task Timeline
+// @Description This api for the ordered lifecycle events of a task with the time elapsed between them
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param id path string true "Task Id"
+// @Success 200  {object}  dto.TaskTimeline
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 404  {object}  appErr.ErrSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /tasks/{id}/events [get]
+func (t TaskHttpApp) MakeGetEvents() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		if _, err := uuid.Parse(ginCtx.Param("id")); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		taskEntity, events, err := t.userSvc.Events(ginCtx.Request.Context(), ginCtx.Param("id"))
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		appErr.OKResponse(ginCtx, transform.TaskEventsToTimeline(taskEntity, events))
+	}
+}
+
+// MakeGetAll
+// @Schemes
+// @Summary Get Tasks
+// @Description This api for list tasks with filters, sorting and pagination
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param  query query dto.GetTaskRequest false "Filters, sorting and pagination"
+// @Success 200  {object}  appErr.ListResponse{items=[]dto.Task}
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /tasks [get]
+func (t TaskHttpApp) MakeGetAll() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		var req dto.GetTaskRequest
+		if err := ginCtx.ShouldBindQuery(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := validation.BindStringSlices(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := req.Validate(ginCtx.Request.Context()); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		pagination, err := utiles.PaginationNormalizer(req.Pagination, ginCtx.Request.Context())
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		filter := transform.GetTaskRequestToFilter(req)
+		tasks, total, err := t.userSvc.List(ginCtx.Request.Context(), filter, utiles.PaginationToPortion(pagination))
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		appErr.OKResponse(ginCtx, appErr.PaginationAndSortListResponse(
+			transform.TasksEntityToTasksDto(tasks),
+			total,
+			int64(pagination.PageSize),
+			int64(pagination.Page),
+			filter.SortBy,
+			filter.SortType,
+		))
+	}
+}
+
+// MakeGetDeadLetters
+// @Schemes
+// @Summary Get Dead Letter Tasks
+// @Description This api for list tasks which used up all of their attempts
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Content-Type application/json
+// @Param  query query dto.GetDeadLetterTaskRequest false "Sorting and pagination"
+// @Success 200  {object}  appErr.ListResponse{items=[]dto.Task}
+// @Failure 400  {object}  appErr.ErrSwaggerResponse
+// @Failure 422  {object}  appErr.ErrValidationSwaggerResponse
+// @Failure 500  {object}  appErr.ErrSwaggerResponse
+// @Router /tasks/dead-letters [get]
+func (t TaskHttpApp) MakeGetDeadLetters() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		var req dto.GetDeadLetterTaskRequest
+		if err := ginCtx.ShouldBindQuery(&req); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EBadArg,
+			})
+			return
+		}
+
+		if err := req.Validate(ginCtx.Request.Context()); err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		pagination, err := utiles.PaginationNormalizer(req.Pagination, ginCtx.Request.Context())
+		if err != nil {
+			appErr.HandelError(ginCtx, &appErr.Error{
+				Cause:   err,
+				Message: err.Error(),
+				Class:   appErr.EValidation,
+			})
+			return
+		}
+
+		filter := transform.GetDeadLetterTaskRequestToFilter(req)
+		tasks, total, err := t.userSvc.List(ginCtx.Request.Context(), filter, utiles.PaginationToPortion(pagination))
+		if err != nil {
+			appErr.HandelError(ginCtx, err)
+			return
+		}
+
+		appErr.OKResponse(ginCtx, appErr.PaginationAndSortListResponse(
+			transform.TasksEntityToTasksDto(tasks),
+			total,
+			int64(pagination.PageSize),
+			int64(pagination.Page),
+			filter.SortBy,
+			filter.SortType,
+		))
+	}
+}
+
+// admit asks the pool for room before a task is stored, a refusal tells the client when to retry
+func (t TaskHttpApp) admit(ctx context.Context) error {
+	err := t.poolWorkerHelper.Admit(ctx)
+	switch {
+	case errors.Is(err, pool.ErrPoolFull):
+		return &appErr.Error{
+			Cause:      err,
+			Message:    err.Error(),
+			Class:      appErr.ETooManyRequests,
+			RetryAfter: t.poolWorkerHelper.RetryAfter(),
+		}
+	case errors.Is(err, pool.ErrPoolDraining), errors.Is(err, pool.ErrPoolStopped):
+		return &appErr.Error{
+			Cause:      err,
+			Message:    err.Error(),
+			Class:      appErr.EUnavailable,
+			RetryAfter: t.poolWorkerHelper.RetryAfter(),
+		}
+	case err != nil:
+		return &appErr.Error{
+			Cause:   err,
+			Message: err.Error(),
+			Class:   appErr.ETimeout,
+		}
+	}
+
+	return nil
+}
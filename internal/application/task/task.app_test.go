@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thealiakbari/task-pool-system/internal/application/task/domain/dto"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/notifier"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+)
+
+// fakeTaskService serves a single task from memory and reports every read of it
+type fakeTaskService struct {
+	task.TaskService
+	reads chan struct{}
+
+	mu    sync.Mutex
+	task  entity.Task
+	count int
+}
+
+func (f *fakeTaskService) GetByIdOrEmpty(ctx context.Context, id string) (entity.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.count++
+	select {
+	case f.reads <- struct{}{}:
+	default:
+	}
+
+	if f.task.Id.String() != id {
+		return entity.Task{}, nil
+	}
+	return f.task, nil
+}
+
+func (f *fakeTaskService) readCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.count
+}
+
+func (f *fakeTaskService) setStatus(status entity.Status) entity.Task {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.task.Status = status
+	return f.task
+}
+
+func TestWait_ReturnsOnceQueuedTaskIsCancelled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	queued := entity.Task{Title: "test", Status: entity.StatusPending}
+	queued.Id = uuid.New()
+	svc := &fakeTaskService{task: queued, reads: make(chan struct{}, 1)}
+	completions := notifier.New(context.Background(), time.Hour)
+	defer completions.Close()
+
+	app := TaskHttpApp{userSvc: svc, completions: completions}
+	router := gin.New()
+	router.GET("/tasks/:id/wait", app.MakeWait())
+
+	recorder := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		request := httptest.NewRequest(http.MethodGet, "/tasks/"+queued.Id.String()+"/wait?timeout=30s", nil)
+		router.ServeHTTP(recorder, request)
+	}()
+
+	<-svc.reads
+	cancelled := svc.setStatus(entity.StatusCancelled)
+	completions.Publish(entity.NewTaskEvent(cancelled, entity.StatusPending, entity.Transition{Reason: "cancelled on request"}))
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the wait does not end once the task is cancelled")
+	}
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var body struct {
+		Payload dto.Task `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, entity.StatusCancelled, body.Payload.Status)
+}
+
+func TestWait_ReadsTaskOnlyWhenItStartsAndEnds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	queued := entity.Task{Title: "test", Status: entity.StatusPending, Retry: entity.RetryPolicy{MaxAttempts: 2}}
+	queued.Id = uuid.New()
+	svc := &fakeTaskService{task: queued, reads: make(chan struct{}, 1)}
+	completions := notifier.New(context.Background(), 0)
+	defer completions.Close()
+
+	app := TaskHttpApp{userSvc: svc, completions: completions}
+	router := gin.New()
+	router.GET("/tasks/:id/wait", app.MakeWait())
+
+	recorder := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		request := httptest.NewRequest(http.MethodGet, "/tasks/"+queued.Id.String()+"/wait?timeout=30s", nil)
+		router.ServeHTTP(recorder, request)
+	}()
+
+	<-svc.reads
+	// A timeout which is retried does not settle the task
+	for _, status := range []entity.Status{entity.StatusRunning, entity.StatusTimedOut, entity.StatusPending, entity.StatusRunning} {
+		completions.Publish(entity.NewTaskEvent(svc.setStatus(status), "", entity.Transition{}))
+	}
+
+	select {
+	case <-done:
+		t.Fatal("the wait ends before the task settles")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, 1, svc.readCount())
+
+	completions.Publish(entity.NewTaskEvent(svc.setStatus(entity.StatusCompleted), entity.StatusRunning, entity.Transition{}))
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the wait does not end once the task completes")
+	}
+
+	assert.Equal(t, 2, svc.readCount())
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
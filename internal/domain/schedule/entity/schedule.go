@@ -0,0 +1,172 @@
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	taskEntity "github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
+)
+
+// CatchUpPolicy decides what happens to the runs which were missed while no executor was firing the schedule
+type CatchUpPolicy string
+
+const (
+	// CatchUpSkip drops the missed runs, only a run which is late by less than MisfireGrace still fires
+	CatchUpSkip CatchUpPolicy = "SKIP"
+	// CatchUpOnce fires a single task for all of the missed runs
+	CatchUpOnce CatchUpPolicy = "ONCE"
+	// CatchUpAll fires one task per missed run, at most MaxCatchUpRuns at once
+	CatchUpAll CatchUpPolicy = "ALL"
+)
+
+const (
+	MisfireGrace   = time.Minute
+	MaxCatchUpRuns = 100
+)
+
+var ErrNotScheduled = errors.New("schedule has no next run")
+
+// TaskTemplate holds the fields every task created by a schedule starts with
+type TaskTemplate struct {
+	Title       string                 `gorm:"column:title;type:varchar(255);not null" validate:"required"`
+	Description string                 `gorm:"column:description;type:text;not null" validate:"required"`
+	Type        string                 `gorm:"column:type;type:varchar(64);not null" validate:"required"`
+	Payload     json.RawMessage        `gorm:"column:payload;type:jsonb" validate:"max=65536"`
+	Priority    int                    `gorm:"column:priority;not null" validate:"min=0,max=9"`
+	Retry       taskEntity.RetryPolicy `gorm:"embedded"`
+	Timeout     time.Duration          `gorm:"column:timeout;not null" validate:"min=0"`
+}
+
+type Schedule struct {
+	db.UniversalModel
+	Name       string        `gorm:"column:name;type:varchar(255);not null" validate:"required"`
+	Expression string        `gorm:"column:expression;type:varchar(255);not null" validate:"required"`
+	Timezone   string        `gorm:"column:timezone;type:varchar(64);not null" validate:"required,timezone"`
+	Template   TaskTemplate  `gorm:"embedded;embeddedPrefix:task_"`
+	CatchUp    CatchUpPolicy `gorm:"column:catch_up;type:varchar(16);not null" validate:"oneof=SKIP ONCE ALL"`
+	NoOverlap  bool          `gorm:"column:no_overlap;not null"`
+	Paused     bool          `gorm:"column:paused;not null"`
+	NextRunAt  *time.Time    `gorm:"column:next_run_at"`
+	LastRunAt  *time.Time    `gorm:"column:last_run_at"`
+	LastTaskId *uuid.UUID    `gorm:"column:last_task_id;type:uuid"`
+}
+
+func (s Schedule) Validate(ctx context.Context) error {
+	if err := validation.Validate(ctx, s); err != nil {
+		return &appErr.Error{
+			Cause:   err,
+			Message: err.Error(),
+			Class:   appErr.EValidation,
+		}
+	}
+
+	if _, err := cron.ParseStandard(s.Expression); err != nil {
+		return &appErr.Error{
+			Cause:   err,
+			Message: "invalid cron expression: " + err.Error(),
+			Class:   appErr.EValidation,
+		}
+	}
+
+	return nil
+}
+
+// Next returns the first run of the schedule after the given time in the schedule's timezone
+func (s Schedule) Next(after time.Time) (time.Time, error) {
+	expression, err := cron.ParseStandard(s.Expression)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := expression.Next(after.In(location))
+	if next.IsZero() {
+		return time.Time{}, ErrNotScheduled
+	}
+
+	return next, nil
+}
+
+// Plan returns the runs which have to fire at now according to the catch-up policy and the run which
+// follows them
+func (s Schedule) Plan(now time.Time) (runs []time.Time, next time.Time, err error) {
+	if s.NextRunAt == nil {
+		next, err = s.Next(now)
+		return nil, next, err
+	}
+
+	due := make([]time.Time, 0)
+	next = *s.NextRunAt
+	for !next.After(now) && len(due) < MaxCatchUpRuns {
+		due = append(due, next)
+		if next, err = s.Next(next); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+
+	// Runs beyond the catch-up limit are dropped
+	if !next.After(now) {
+		if next, err = s.Next(now); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+
+	if len(due) == 0 {
+		return nil, next, nil
+	}
+
+	last := due[len(due)-1]
+	switch s.CatchUp {
+	case CatchUpAll:
+		runs = due
+	case CatchUpSkip:
+		if now.Sub(last) <= MisfireGrace {
+			runs = []time.Time{last}
+		}
+	default:
+		runs = []time.Time{last}
+	}
+
+	return runs, next, nil
+}
+
+// NewTask builds the task of the run at the given time
+func (t TaskTemplate) NewTask(runAt time.Time) taskEntity.Task {
+	task := taskEntity.Task{
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      taskEntity.StatusPending,
+		Type:        t.Type,
+		Payload:     t.Payload,
+		Priority:    t.Priority,
+		Retry:       t.Retry,
+		Timeout:     t.Timeout,
+	}
+
+	if t.Type == handler.TypeSleep {
+		task.Duration, _ = handler.ParseSleepPayload(t.Payload)
+	}
+
+	return task
+}
+
+// ScheduleFilter holds the criteria used to list schedules, every empty field is ignored
+type ScheduleFilter struct {
+	Names    []string
+	Paused   *bool
+	SortBy   string
+	SortType request.SortType
+}
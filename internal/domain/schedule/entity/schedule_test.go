@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+)
+
+func hourly(catchUp CatchUpPolicy, nextRunAt time.Time) Schedule {
+	return Schedule{
+		Name:       "hourly",
+		Expression: "0 * * * *",
+		Timezone:   "UTC",
+		Template:   TaskTemplate{Title: "report", Description: "hourly report", Type: "sleep"},
+		CatchUp:    catchUp,
+		NextRunAt:  &nextRunAt,
+	}
+}
+
+func TestSchedule_ValidateExpression(t *testing.T) {
+	s := hourly(CatchUpOnce, time.Now())
+	assert.NoError(t, s.Validate(context.Background()))
+
+	s.Expression = "every hour"
+	err := s.Validate(context.Background())
+	assert.True(t, appErr.IsValidation(err))
+}
+
+func TestSchedule_NextUsesTimezone(t *testing.T) {
+	s := hourly(CatchUpOnce, time.Now())
+	s.Expression = "30 9 * * *"
+	s.Timezone = "Asia/Tehran"
+
+	next, err := s.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestSchedule_PlanNotDue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	s := hourly(CatchUpOnce, now.Add(30*time.Minute))
+
+	runs, next, err := s.Plan(now)
+	assert.NoError(t, err)
+	assert.Empty(t, runs)
+	assert.Equal(t, now.Add(30*time.Minute), next.UTC())
+}
+
+func TestSchedule_PlanCatchUp(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	missedFrom := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+	expectedNext := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	runs, next, err := hourly(CatchUpAll, missedFrom).Plan(now)
+	assert.NoError(t, err)
+	assert.Len(t, runs, 4)
+	assert.Equal(t, expectedNext, next.UTC())
+
+	runs, next, err = hourly(CatchUpOnce, missedFrom).Plan(now)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}, utc(runs))
+	assert.Equal(t, expectedNext, next.UTC())
+
+	runs, next, err = hourly(CatchUpSkip, missedFrom).Plan(now)
+	assert.NoError(t, err)
+	assert.Empty(t, runs)
+	assert.Equal(t, expectedNext, next.UTC())
+}
+
+func TestSchedule_PlanSkipFiresOnTimeRun(t *testing.T) {
+	due := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	runs, _, err := hourly(CatchUpSkip, due).Plan(due.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{due}, utc(runs))
+}
+
+func TestSchedule_PlanLimitsCatchUp(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	runs, next, err := hourly(CatchUpAll, now.AddDate(-1, 0, 0)).Plan(now)
+	assert.NoError(t, err)
+	assert.Len(t, runs, MaxCatchUpRuns)
+	assert.Equal(t, time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func utc(times []time.Time) []time.Time {
+	res := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		res = append(res, t.UTC())
+	}
+	return res
+}
@@ -0,0 +1,181 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+	taskEntity "github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/scheduler"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/schedule"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+)
+
+const (
+	defaultInterval = time.Second
+	defaultBatch    = 100
+)
+
+type Deps struct {
+	DB              db.Transactor
+	ScheduleService schedule.ScheduleService
+	TaskService     task.TaskService
+	Pool            scheduler.Submitter
+}
+
+// Runner fires the due schedules, every run creates a task from the schedule's template and submits
+// it to the pool. A run is claimed by moving the schedule's next run forward in the transaction which
+// creates its tasks, so several executors never fire the same run twice and a run whose task can not
+// be created is fired again on the next tick.
+type Runner struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	deps     Deps
+	interval time.Duration
+	batch    int
+}
+
+func New(parent context.Context, interval time.Duration) *Runner {
+	ctx, cancel := context.WithCancel(parent)
+
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &Runner{
+		ctx:      ctx,
+		cancel:   cancel,
+		interval: interval,
+		batch:    defaultBatch,
+	}
+}
+
+func (r *Runner) Start(deps Deps) {
+	log.Printf("[CRON] starting, checking due schedules every %s", r.interval)
+	r.deps = deps
+
+	r.wg.Add(1)
+	go r.run()
+}
+
+func (r *Runner) Shutdown() {
+	log.Println("[CRON] shutdown initiated")
+	r.cancel()
+	r.wg.Wait()
+	log.Println("[CRON] shutdown completed")
+}
+
+func (r *Runner) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.tick(time.Now())
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) tick(now time.Time) {
+	schedules, err := r.deps.ScheduleService.FindDue(r.ctx, now, r.batch)
+	if err != nil {
+		return
+	}
+
+	for _, s := range schedules {
+		if r.ctx.Err() != nil {
+			return
+		}
+		r.fire(s, now)
+	}
+}
+
+func (r *Runner) fire(s entity.Schedule, now time.Time) {
+	runs, next, err := s.Plan(now)
+	if err != nil {
+		log.Printf("[CRON] cannot plan schedule %s: %v", s.Id, err)
+		return
+	}
+
+	if s.NoOverlap && len(runs) > 0 {
+		if r.overlaps(s) {
+			log.Printf("[CRON] schedule %s skipped %d runs, task %s is still active", s.Id, len(runs), s.LastTaskId)
+			runs = nil
+		} else {
+			// Catching up several runs at once would overlap them as well
+			runs = runs[len(runs)-1:]
+		}
+	}
+
+	var lastRunAt *time.Time
+	if len(runs) > 0 {
+		lastRunAt = &runs[len(runs)-1]
+	}
+
+	var created []taskEntity.Task
+	err = r.deps.DB.InTx(r.ctx, func(ctx context.Context) error {
+		created = nil
+
+		ok, err := r.deps.ScheduleService.Advance(ctx, s.Id, *s.NextRunAt, next, lastRunAt)
+		if err != nil || !ok {
+			return err
+		}
+
+		for _, runAt := range runs {
+			task, err := r.deps.TaskService.Create(ctx, s.Template.NewTask(runAt))
+			if err != nil {
+				return fmt.Errorf("cannot create the task of %s: %w", runAt, err)
+			}
+			created = append(created, task)
+		}
+
+		if len(created) == 0 {
+			return nil
+		}
+
+		// The no-overlap check of the next run reads the last task
+		return r.deps.ScheduleService.SetLastTask(ctx, s.Id, created[len(created)-1].Id)
+	})
+	if err != nil {
+		log.Printf("[CRON] schedule %s is fired again on the next tick: %v", s.Id, err)
+		return
+	}
+
+	// The pool leases the tasks from the database, they are submitted once they are committed
+	for i := range created {
+		if err := r.deps.Pool.Submit(&created[i]); err != nil {
+			log.Printf("[CRON] task %s is left to the durable backlog: %v", created[i].Id, err)
+		}
+	}
+
+	if len(created) > 0 {
+		log.Printf("[CRON] schedule %s fired %d runs, next run at %s", s.Id, len(created), next)
+	}
+}
+
+// overlaps reports whether the last task of the schedule has not finished yet, a task which can not be
+// looked up counts as active so a database blip never leads to overlapping runs
+func (r *Runner) overlaps(s entity.Schedule) bool {
+	if s.LastTaskId == nil {
+		return false
+	}
+
+	last, err := r.deps.TaskService.GetByIdOrEmpty(r.ctx, s.LastTaskId.String())
+	if err != nil {
+		return true
+	}
+
+	return last.Id != uuid.Nil && !last.IsSettled()
+}
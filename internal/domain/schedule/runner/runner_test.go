@@ -0,0 +1,233 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+	taskEntity "github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/schedule"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+)
+
+type fakeScheduleService struct {
+	schedule.ScheduleService
+
+	mu       sync.Mutex
+	claimed  map[uuid.UUID]time.Time
+	lastTask map[uuid.UUID]uuid.UUID
+	// lastTaskErr fails every SetLastTask
+	lastTaskErr error
+}
+
+func newFakeScheduleService() *fakeScheduleService {
+	return &fakeScheduleService{
+		claimed:  make(map[uuid.UUID]time.Time),
+		lastTask: make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+func (f *fakeScheduleService) Advance(ctx context.Context, id uuid.UUID, from time.Time, next time.Time, lastRunAt *time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.claimed[id]; ok {
+		return false, nil
+	}
+	f.claimed[id] = next
+	return true, nil
+}
+
+func (f *fakeScheduleService) SetLastTask(ctx context.Context, id uuid.UUID, taskId uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lastTaskErr != nil {
+		return f.lastTaskErr
+	}
+	f.lastTask[id] = taskId
+	return nil
+}
+
+type fakeTaskService struct {
+	task.TaskService
+
+	mu      sync.Mutex
+	created []taskEntity.Task
+	tasks   map[uuid.UUID]taskEntity.Task
+	// failures is the number of creates which fail before the next one succeeds
+	failures int
+}
+
+func (f *fakeTaskService) Create(ctx context.Context, in taskEntity.Task) (taskEntity.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return taskEntity.Task{}, errors.New("db down")
+	}
+	in.Id = uuid.New()
+	f.created = append(f.created, in)
+	return in, nil
+}
+
+func (f *fakeTaskService) GetByIdOrEmpty(ctx context.Context, id string) (taskEntity.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tasks[uuid.MustParse(id)], nil
+}
+
+type fakePool struct {
+	mu        sync.Mutex
+	submitted int
+}
+
+func (f *fakePool) Submit(task *taskEntity.Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submitted++
+	return nil
+}
+
+// fakeDB runs fn like a transaction over the fakes, a failing fn leaves them as they were
+type fakeDB struct {
+	schedules *fakeScheduleService
+	tasks     *fakeTaskService
+}
+
+func (f fakeDB) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.schedules.mu.Lock()
+	claimed, lastTask := maps.Clone(f.schedules.claimed), maps.Clone(f.schedules.lastTask)
+	f.schedules.mu.Unlock()
+	f.tasks.mu.Lock()
+	created := slices.Clone(f.tasks.created)
+	f.tasks.mu.Unlock()
+
+	err := fn(ctx)
+	if err == nil {
+		return nil
+	}
+
+	f.schedules.mu.Lock()
+	f.schedules.claimed, f.schedules.lastTask = claimed, lastTask
+	f.schedules.mu.Unlock()
+	f.tasks.mu.Lock()
+	f.tasks.created = created
+	f.tasks.mu.Unlock()
+	return err
+}
+
+func newRunner(schedules *fakeScheduleService, tasks *fakeTaskService, pool *fakePool) *Runner {
+	r := New(context.Background(), time.Hour)
+	r.deps = Deps{DB: fakeDB{schedules: schedules, tasks: tasks}, ScheduleService: schedules, TaskService: tasks, Pool: pool}
+	return r
+}
+
+func everyMinute(catchUp entity.CatchUpPolicy, nextRunAt time.Time) entity.Schedule {
+	return entity.Schedule{
+		UniversalModel: db.UniversalModel{Id: uuid.New()},
+		Name:           "every minute",
+		Expression:     "* * * * *",
+		Timezone:       "UTC",
+		Template:       entity.TaskTemplate{Title: "tick", Description: "tick", Priority: 7},
+		CatchUp:        catchUp,
+		NextRunAt:      &nextRunAt,
+	}
+}
+
+func TestRunner_FireCatchesUpMissedRuns(t *testing.T) {
+	schedules, tasks, pool := newFakeScheduleService(), &fakeTaskService{}, &fakePool{}
+	r := newRunner(schedules, tasks, pool)
+
+	now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+	s := everyMinute(entity.CatchUpAll, now.Add(-3*time.Minute).Truncate(time.Minute))
+	r.fire(s, now)
+
+	assert.Len(t, tasks.created, 4)
+	assert.Equal(t, 4, pool.submitted)
+	assert.Equal(t, 7, tasks.created[0].Priority)
+	assert.Equal(t, taskEntity.StatusPending, tasks.created[0].Status)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC), schedules.claimed[s.Id].UTC())
+	assert.Equal(t, tasks.created[3].Id, schedules.lastTask[s.Id])
+}
+
+func TestRunner_FireOnlyOnceWhenClaimedElsewhere(t *testing.T) {
+	schedules, tasks, pool := newFakeScheduleService(), &fakeTaskService{}, &fakePool{}
+	r := newRunner(schedules, tasks, pool)
+
+	now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+	s := everyMinute(entity.CatchUpOnce, now.Truncate(time.Minute))
+	r.fire(s, now)
+	r.fire(s, now)
+
+	assert.Len(t, tasks.created, 1)
+}
+
+func TestRunner_NoOverlapSkipsWhileLastTaskIsActive(t *testing.T) {
+	running := taskEntity.Task{UniversalModel: db.UniversalModel{Id: uuid.New()}, Status: taskEntity.StatusRunning}
+	schedules, pool := newFakeScheduleService(), &fakePool{}
+	tasks := &fakeTaskService{tasks: map[uuid.UUID]taskEntity.Task{running.Id: running}}
+	r := newRunner(schedules, tasks, pool)
+
+	now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+	s := everyMinute(entity.CatchUpAll, now.Add(-3*time.Minute).Truncate(time.Minute))
+	s.NoOverlap = true
+	s.LastTaskId = &running.Id
+	r.fire(s, now)
+
+	assert.Empty(t, tasks.created)
+	assert.Contains(t, schedules.claimed, s.Id)
+}
+
+func TestRunner_NoOverlapFiresASingleRun(t *testing.T) {
+	schedules, tasks, pool := newFakeScheduleService(), &fakeTaskService{}, &fakePool{}
+	r := newRunner(schedules, tasks, pool)
+
+	now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+	s := everyMinute(entity.CatchUpAll, now.Add(-3*time.Minute).Truncate(time.Minute))
+	s.NoOverlap = true
+	r.fire(s, now)
+
+	assert.Len(t, tasks.created, 1)
+}
+
+func TestRunner_FailedCreateFiresTheRunAgain(t *testing.T) {
+	schedules, pool := newFakeScheduleService(), &fakePool{}
+	tasks := &fakeTaskService{failures: 1}
+	r := newRunner(schedules, tasks, pool)
+
+	now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+	s := everyMinute(entity.CatchUpAll, now.Add(-time.Minute).Truncate(time.Minute))
+	r.fire(s, now)
+
+	assert.Empty(t, tasks.created)
+	assert.NotContains(t, schedules.claimed, s.Id)
+	assert.Zero(t, pool.submitted)
+
+	r.fire(s, now)
+	assert.Len(t, tasks.created, 2)
+	assert.Contains(t, schedules.claimed, s.Id)
+	assert.Equal(t, tasks.created[1].Id, schedules.lastTask[s.Id])
+	assert.Equal(t, 2, pool.submitted)
+}
+
+func TestRunner_FailedSetLastTaskFiresTheRunAgain(t *testing.T) {
+	schedules, tasks, pool := newFakeScheduleService(), &fakeTaskService{}, &fakePool{}
+	schedules.lastTaskErr = errors.New("db down")
+	r := newRunner(schedules, tasks, pool)
+
+	now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+	s := everyMinute(entity.CatchUpOnce, now.Truncate(time.Minute))
+	s.NoOverlap = true
+	r.fire(s, now)
+
+	assert.Empty(t, tasks.created)
+	assert.NotContains(t, schedules.claimed, s.Id)
+	assert.Zero(t, pool.submitted)
+}
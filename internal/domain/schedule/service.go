@@ -0,0 +1,321 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
+	scheduleInterface "github.com/thealiakbari/task-pool-system/internal/ports/inbound/schedule"
+	"github.com/thealiakbari/task-pool-system/internal/ports/outbound/schedule"
+	"github.com/thealiakbari/task-pool-system/pkg/common/logger"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+)
+
+var (
+	ErrEmptyId = &appErr.Error{
+		Cause:   errors.New("id must not be empty"),
+		Message: "id must not be empty",
+		Class:   appErr.EBadArg,
+	}
+	ErrScheduleNotFound = &appErr.Error{
+		Cause:   errors.New("schedule not found"),
+		Message: "schedule not found",
+		Class:   appErr.ENotFound,
+	}
+	ErrUnknownTaskType = &appErr.Error{
+		Cause:   handler.ErrUnknownType,
+		Message: "no handler is registered for the task type",
+		Class:   appErr.EValidation,
+	}
+)
+
+// sortColumns maps the public sort keys to the schedules table columns
+var sortColumns = map[string]string{
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+	"name":      "name",
+	"nextRunAt": "next_run_at",
+}
+
+type ScheduleConfig struct {
+	Logger       logger.Logger
+	ScheduleRepo schedule.ScheduleRepository
+	// Handlers is used to reject templates which no worker can run, the type is not checked when it is nil
+	Handlers *handler.Registry
+}
+
+type scheduleService struct {
+	ScheduleConfig
+}
+
+func NewScheduleService(config ScheduleConfig) scheduleInterface.ScheduleService {
+	u := scheduleService{config}
+	u.Logger = config.Logger.ForService(u)
+	return u
+}
+
+func (u scheduleService) Create(ctx context.Context, req entity.Schedule) (res entity.Schedule, err error) {
+	if err = u.validate(ctx, req); err != nil {
+		return entity.Schedule{}, err
+	}
+
+	if err = plan(&req, time.Now()); err != nil {
+		return entity.Schedule{}, err
+	}
+
+	res, err = u.ScheduleRepo.Create(ctx, req)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot create schedule: %v", err)
+		return entity.Schedule{}, err
+	}
+
+	return res, nil
+}
+
+// Update replaces the definition of the schedule and plans its next run again, the run state is kept
+func (u scheduleService) Update(ctx context.Context, req entity.Schedule) (res entity.Schedule, err error) {
+	res, err = u.find(ctx, req.Id.String())
+	if err != nil {
+		return entity.Schedule{}, err
+	}
+
+	res.Name = req.Name
+	res.Expression = req.Expression
+	res.Timezone = req.Timezone
+	res.Template = req.Template
+	res.CatchUp = req.CatchUp
+	res.NoOverlap = req.NoOverlap
+
+	if err = u.validate(ctx, res); err != nil {
+		return entity.Schedule{}, err
+	}
+
+	if err = plan(&res, time.Now()); err != nil {
+		return entity.Schedule{}, err
+	}
+
+	err = u.ScheduleRepo.Update(ctx, res)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot update schedule %s: %v", res.Id, err)
+		return entity.Schedule{}, err
+	}
+
+	return res, nil
+}
+
+func (u scheduleService) GetByIdOrEmpty(ctx context.Context, id string) (res entity.Schedule, err error) {
+	if id == "" {
+		return entity.Schedule{}, ErrEmptyId
+	}
+
+	res, err = u.ScheduleRepo.FindByIdOrEmpty(ctx, id)
+	if err != nil {
+		return entity.Schedule{}, err
+	}
+
+	return res, nil
+}
+
+func (u scheduleService) List(ctx context.Context, filter entity.ScheduleFilter, portion request.Portion) (res []entity.Schedule, total int64, err error) {
+	query := filterQuery(filter)
+
+	total, err = u.ScheduleRepo.FilterCount(ctx, query)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot count schedules: %v", err)
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return []entity.Schedule{}, 0, nil
+	}
+
+	res, err = u.ScheduleRepo.FilterFind(ctx, query, filterOrder(filter), portion.Limit, portion.Offset)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot find schedules: %v", err)
+		return nil, 0, err
+	}
+
+	return res, total, nil
+}
+
+// Pause stops the schedule from firing, the runs which fall in the pause are never fired
+func (u scheduleService) Pause(ctx context.Context, id string) (res entity.Schedule, err error) {
+	res, err = u.find(ctx, id)
+	if err != nil {
+		return entity.Schedule{}, err
+	}
+
+	if res.Paused {
+		return res, nil
+	}
+
+	res.Paused = true
+	res.NextRunAt = nil
+	err = u.ScheduleRepo.Update(ctx, res)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot pause schedule %s: %v", id, err)
+		return entity.Schedule{}, err
+	}
+
+	return res, nil
+}
+
+func (u scheduleService) Resume(ctx context.Context, id string) (res entity.Schedule, err error) {
+	res, err = u.find(ctx, id)
+	if err != nil {
+		return entity.Schedule{}, err
+	}
+
+	if !res.Paused {
+		return res, nil
+	}
+
+	res.Paused = false
+	if err = plan(&res, time.Now()); err != nil {
+		return entity.Schedule{}, err
+	}
+
+	err = u.ScheduleRepo.Update(ctx, res)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot resume schedule %s: %v", id, err)
+		return entity.Schedule{}, err
+	}
+
+	return res, nil
+}
+
+func (u scheduleService) Delete(ctx context.Context, id string) (err error) {
+	if id == "" {
+		return ErrEmptyId
+	}
+
+	err = u.ScheduleRepo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u scheduleService) FindDue(ctx context.Context, now time.Time, limit int) (res []entity.Schedule, err error) {
+	res, err = u.ScheduleRepo.FindDue(ctx, now, limit)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot find due schedules: %v", err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (u scheduleService) Advance(ctx context.Context, id uuid.UUID, from time.Time, next time.Time, lastRunAt *time.Time) (ok bool, err error) {
+	ok, err = u.ScheduleRepo.Advance(ctx, id, from, next, lastRunAt)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot advance schedule %s: %v", id, err)
+		return false, err
+	}
+
+	return ok, nil
+}
+
+func (u scheduleService) SetLastTask(ctx context.Context, id uuid.UUID, taskId uuid.UUID) (err error) {
+	err = u.ScheduleRepo.SetLastTask(ctx, id, taskId)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot set the last task of schedule %s: %v", id, err)
+		return err
+	}
+
+	return nil
+}
+
+func (u scheduleService) find(ctx context.Context, id string) (res entity.Schedule, err error) {
+	res, err = u.GetByIdOrEmpty(ctx, id)
+	if err != nil {
+		return entity.Schedule{}, err
+	}
+
+	if res.Id == uuid.Nil {
+		return entity.Schedule{}, ErrScheduleNotFound
+	}
+
+	return res, nil
+}
+
+func (u scheduleService) validate(ctx context.Context, s entity.Schedule) error {
+	if err := s.Validate(ctx); err != nil {
+		u.Logger.Warnf(ctx, "validation error:%v", err)
+		return err
+	}
+
+	if u.Handlers != nil && !u.Handlers.Has(s.Template.Type) {
+		u.Logger.Warnf(ctx, "unknown task type:%s", s.Template.Type)
+		return ErrUnknownTaskType
+	}
+
+	return nil
+}
+
+// plan sets the next run of an active schedule to its first run after now
+func plan(s *entity.Schedule, now time.Time) error {
+	if s.Paused {
+		s.NextRunAt = nil
+		return nil
+	}
+
+	next, err := s.Next(now)
+	if err != nil {
+		return &appErr.Error{
+			Cause:   err,
+			Message: err.Error(),
+			Class:   appErr.EValidation,
+		}
+	}
+
+	s.NextRunAt = &next
+	return nil
+}
+
+// filterQuery builds the `[]any{condition, args...}` form which is expected by the repository filters
+func filterQuery(filter entity.ScheduleFilter) []any {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+
+	if len(filter.Names) > 0 {
+		names := make([]string, 0, len(filter.Names))
+		for _, name := range filter.Names {
+			names = append(names, "name ILIKE ?")
+			args = append(args, "%"+name+"%")
+		}
+		conditions = append(conditions, "("+strings.Join(names, " OR ")+")")
+	}
+
+	if filter.Paused != nil {
+		conditions = append(conditions, "paused = ?")
+		args = append(args, *filter.Paused)
+	}
+
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	return append([]any{strings.Join(conditions, " AND ")}, args...)
+}
+
+func filterOrder(filter entity.ScheduleFilter) string {
+	column, ok := sortColumns[filter.SortBy]
+	if !ok {
+		column = sortColumns["createdAt"]
+	}
+
+	sortType := request.SortTypeDESC
+	if strings.ToUpper(filter.SortType) == request.SortTypeASC {
+		sortType = request.SortTypeASC
+	}
+
+	return fmt.Sprintf("%s %s, id %s", column, sortType, sortType)
+}
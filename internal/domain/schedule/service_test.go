@@ -0,0 +1,167 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+	"github.com/thealiakbari/task-pool-system/pkg/common/logger"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+)
+
+type mockRepo struct {
+	mock.Mock
+}
+
+func (m *mockRepo) Create(ctx context.Context, in entity.Schedule) (entity.Schedule, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(entity.Schedule), args.Error(1)
+}
+
+func (m *mockRepo) Update(ctx context.Context, in entity.Schedule) error {
+	args := m.Called(ctx, in)
+	return args.Error(0)
+}
+
+func (m *mockRepo) FindByIdOrEmpty(ctx context.Context, id string) (entity.Schedule, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.Schedule), args.Error(1)
+}
+
+func (m *mockRepo) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockRepo) FilterFind(ctx context.Context, query []any, order string, limit int, offset int) ([]entity.Schedule, error) {
+	args := m.Called(ctx, query, order, limit, offset)
+	return args.Get(0).([]entity.Schedule), args.Error(1)
+}
+
+func (m *mockRepo) FilterCount(ctx context.Context, query []any) (int64, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockRepo) FindDue(ctx context.Context, now time.Time, limit int) ([]entity.Schedule, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]entity.Schedule), args.Error(1)
+}
+
+func (m *mockRepo) Advance(ctx context.Context, id uuid.UUID, from time.Time, next time.Time, lastRunAt *time.Time) (bool, error) {
+	args := m.Called(ctx, id, from, next, lastRunAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockRepo) SetLastTask(ctx context.Context, id uuid.UUID, taskId uuid.UUID) error {
+	args := m.Called(ctx, id, taskId)
+	return args.Error(0)
+}
+
+func newSchedule() entity.Schedule {
+	return entity.Schedule{
+		Name:       "nightly",
+		Expression: "0 3 * * *",
+		Timezone:   "UTC",
+		Template:   entity.TaskTemplate{Title: "cleanup", Description: "nightly cleanup", Type: "sleep"},
+		CatchUp:    entity.CatchUpOnce,
+	}
+}
+
+func TestCreate_PlansNextRun(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewScheduleService(ScheduleConfig{
+		Logger:       log,
+		ScheduleRepo: repo,
+	})
+
+	repo.On("Create", ctx, mock.MatchedBy(func(s entity.Schedule) bool {
+		return s.NextRunAt != nil && s.NextRunAt.After(time.Now()) && s.NextRunAt.Hour() == 3
+	})).Return(newSchedule(), nil)
+
+	_, err = service.Create(ctx, newSchedule())
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestCreate_InvalidExpression(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewScheduleService(ScheduleConfig{
+		Logger:       log,
+		ScheduleRepo: repo,
+	})
+
+	item := newSchedule()
+	item.Expression = "61 * * * *"
+
+	_, err = service.Create(ctx, item)
+	assert.True(t, appErr.IsValidation(err))
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPause_ClearsNextRun(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewScheduleService(ScheduleConfig{
+		Logger:       log,
+		ScheduleRepo: repo,
+	})
+
+	item := newSchedule()
+	item.Id = uuid.New()
+	next := time.Now().Add(time.Hour)
+	item.NextRunAt = &next
+	paused := item
+	paused.Paused = true
+	paused.NextRunAt = nil
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+	repo.On("Update", ctx, paused).Return(nil)
+
+	res, err := service.Pause(ctx, item.Id.String())
+	assert.NoError(t, err)
+	assert.True(t, res.Paused)
+	assert.Nil(t, res.NextRunAt)
+	repo.AssertExpectations(t)
+}
+
+func TestResume_NotFound(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewScheduleService(ScheduleConfig{
+		Logger:       log,
+		ScheduleRepo: repo,
+	})
+
+	id := uuid.NewString()
+	repo.On("FindByIdOrEmpty", ctx, id).Return(entity.Schedule{}, nil)
+
+	_, err = service.Resume(ctx, id)
+	assert.ErrorIs(t, err, ErrScheduleNotFound)
+	assert.True(t, appErr.IsNotFound(err))
+}
@@ -0,0 +1,135 @@
+package bus
+
+import (
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+)
+
+var (
+	ErrBusClosed = errors.New("event bus is closed")
+	ErrLagging   = errors.New("subscriber fell too far behind the events")
+)
+
+// Filter picks the events a subscription receives, entity.EventFilter is one. It is called while the bus is
+// locked so it must be cheap and must not change once handed to the bus.
+type Filter interface {
+	Matches(event entity.TaskEvent) bool
+}
+
+// Subscription receives the events of the bus which match its filter, its channel is closed once the
+// subscription is closed, the bus is closed or the subscriber falls too far behind
+type Subscription struct {
+	filter Filter
+	events chan entity.TaskEvent
+	bus    *Bus
+	err    error
+}
+
+func (s *Subscription) Events() <-chan entity.TaskEvent {
+	return s.events
+}
+
+// Err tells why the bus ended the subscription, it is nil while the subscription is open or after Close
+func (s *Subscription) Err() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	return s.err
+}
+
+// SetFilter replaces the filter of the subscription, the events published before are delivered as they were
+// picked
+func (s *Subscription) SetFilter(filter Filter) {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.filter = filter
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.bus.drop(s, nil)
+}
+
+// Bus hands the task events published in this process to the subscribers. Publish never blocks: a subscriber
+// whose buffer is full is dropped, it is expected to catch up from the stored events.
+type Bus struct {
+	subs   map[*Subscription]struct{}
+	buffer int
+	closed bool
+
+	mu sync.Mutex
+}
+
+func New(buffer int) *Bus {
+	return &Bus{
+		subs:   make(map[*Subscription]struct{}),
+		buffer: buffer,
+	}
+}
+
+func (b *Bus) Publish(event entity.TaskEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("[BUS] dropping a subscriber which fell %d events behind", b.buffer)
+			b.drop(sub, ErrLagging)
+		}
+	}
+}
+
+// Subscribe returns a subscription to the events which match the filter, the subscription of a closed bus
+// is closed already
+func (b *Bus) Subscribe(filter Filter) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		filter: filter,
+		events: make(chan entity.TaskEvent, b.buffer),
+		bus:    b,
+	}
+	if b.closed {
+		sub.err = ErrBusClosed
+		close(sub.events)
+		return sub
+	}
+
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close ends every subscription, the subscribers stop on their own and the bus refuses new ones
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub, ErrBusClosed)
+	}
+}
+
+// drop must be called while holding the lock
+func (b *Bus) drop(sub *Subscription, err error) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+
+	delete(b.subs, sub)
+	sub.err = err
+	close(sub.events)
+}
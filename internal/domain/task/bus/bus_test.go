@@ -0,0 +1,83 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+)
+
+func newEvent(taskId uuid.UUID, status entity.Status) entity.TaskEvent {
+	return entity.TaskEvent{Id: uuid.New(), TaskId: taskId, FromStatus: entity.StatusRunning, ToStatus: status}
+}
+
+func TestPublish_DeliversMatchingEvents(t *testing.T) {
+	b := New(10)
+	taskId := uuid.New()
+	byTask := b.Subscribe(entity.EventFilter{TaskId: taskId})
+	byStatus := b.Subscribe(entity.EventFilter{Statuses: []entity.Status{entity.StatusFailed}})
+
+	completed := newEvent(taskId, entity.StatusCompleted)
+	failed := newEvent(uuid.New(), entity.StatusFailed)
+	b.Publish(completed)
+	b.Publish(failed)
+
+	assert.Equal(t, completed, <-byTask.Events())
+	assert.Empty(t, byTask.Events())
+	assert.Equal(t, failed, <-byStatus.Events())
+	assert.Empty(t, byStatus.Events())
+}
+
+func TestPublish_DropsSlowSubscriber(t *testing.T) {
+	b := New(1)
+	slow := b.Subscribe(entity.EventFilter{})
+
+	b.Publish(newEvent(uuid.New(), entity.StatusCompleted))
+	b.Publish(newEvent(uuid.New(), entity.StatusCompleted))
+
+	_, ok := <-slow.Events()
+	assert.True(t, ok)
+	_, ok = <-slow.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, slow.Err(), ErrLagging)
+}
+
+func TestClose_EndsSubscriptions(t *testing.T) {
+	b := New(1)
+	sub := b.Subscribe(entity.EventFilter{})
+	sub.Close()
+	sub.Close()
+
+	open := b.Subscribe(entity.EventFilter{})
+	b.Close()
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.NoError(t, sub.Err())
+	_, ok = <-open.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, open.Err(), ErrBusClosed)
+	_, ok = <-b.Subscribe(entity.EventFilter{}).Events()
+	assert.False(t, ok)
+}
+
+func TestSetFilter_ChangesDeliveredEvents(t *testing.T) {
+	b := New(1)
+	first, second := uuid.New(), uuid.New()
+	sub := b.Subscribe(entity.EventFilter{TaskId: first})
+
+	before := newEvent(first, entity.StatusCompleted)
+	b.Publish(before)
+	assert.Equal(t, before, <-sub.Events())
+
+	sub.SetFilter(entity.EventFilter{TaskId: second})
+	b.Publish(newEvent(first, entity.StatusCompleted))
+	b.Publish(newEvent(first, entity.StatusFailed))
+	after := newEvent(second, entity.StatusCompleted)
+	b.Publish(after)
+
+	assert.Equal(t, after, <-sub.Events())
+	assert.Empty(t, sub.Events())
+	assert.NoError(t, sub.Err())
+}
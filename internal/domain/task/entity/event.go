@@ -0,0 +1,102 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventKind string
+
+const (
+	EventScheduled     EventKind = "SCHEDULED"
+	EventQueued        EventKind = "QUEUED"
+	EventStarted       EventKind = "STARTED"
+	EventAttemptFailed EventKind = "ATTEMPT_FAILED"
+	EventTimedOut      EventKind = "TIMED_OUT"
+	EventRetried       EventKind = "RETRIED"
+	EventRedriven      EventKind = "REDRIVEN"
+	EventCompleted     EventKind = "COMPLETED"
+	EventFailed        EventKind = "FAILED"
+	EventCancelled     EventKind = "CANCELLED"
+	EventDeadLettered  EventKind = "DEAD_LETTERED"
+)
+
+// Transition describes why a task changed its status and which worker, if any, moved it
+type Transition struct {
+	WorkerId string
+	Reason   string
+}
+
+// TaskEvent is a single recorded move of a task between two statuses, the events are never updated
+type TaskEvent struct {
+	Id         uuid.UUID `gorm:"column:id;primary_key;type:uuid;default:uuid_generate_v4()"`
+	CreatedAt  time.Time `gorm:"column:created_at;not null"`
+	TaskId     uuid.UUID `gorm:"column:task_id;type:uuid;not null"`
+	FromStatus Status    `gorm:"column:from_status;type:varchar(32);not null"`
+	ToStatus   Status    `gorm:"column:to_status;type:varchar(32);not null"`
+	WorkerId   string    `gorm:"column:worker_id;type:varchar(255);not null"`
+	Reason     string    `gorm:"column:reason;type:text;not null"`
+}
+
+// EventFilter selects the events of a single task when TaskId is set and the events which move a task to
+// one of Statuses when any is given
+type EventFilter struct {
+	TaskId   uuid.UUID
+	Statuses []Status
+}
+
+func (f EventFilter) Matches(event TaskEvent) bool {
+	if f.TaskId != uuid.Nil && f.TaskId != event.TaskId {
+		return false
+	}
+
+	return len(f.Statuses) == 0 || slices.Contains(f.Statuses, event.ToStatus)
+}
+
+// NewTaskEvent records the move of the task from the given status to its current one, the id is set upfront
+// so the event can be published under the id it is stored with
+func NewTaskEvent(task Task, from Status, transition Transition) TaskEvent {
+	return TaskEvent{
+		Id:         uuid.New(),
+		CreatedAt:  time.Now(),
+		TaskId:     task.Id,
+		FromStatus: from,
+		ToStatus:   task.Status,
+		WorkerId:   transition.WorkerId,
+		Reason:     transition.Reason,
+	}
+}
+
+// Kind names the lifecycle step the event stands for, a running task which goes back to pending has failed
+// an attempt and waits for the next one
+func (e TaskEvent) Kind() EventKind {
+	switch e.ToStatus {
+	case StatusScheduled:
+		return EventScheduled
+	case StatusPending:
+		switch e.FromStatus {
+		case StatusRunning:
+			return EventAttemptFailed
+		case StatusTimedOut:
+			return EventRetried
+		case StatusDeadLetter:
+			return EventRedriven
+		default:
+			return EventQueued
+		}
+	case StatusRunning:
+		return EventStarted
+	case StatusTimedOut:
+		return EventTimedOut
+	case StatusCompleted:
+		return EventCompleted
+	case StatusCancelled:
+		return EventCancelled
+	case StatusDeadLetter:
+		return EventDeadLettered
+	default:
+		return EventFailed
+	}
+}
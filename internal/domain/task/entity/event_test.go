@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskEvent_Kind(t *testing.T) {
+	assert.Equal(t, EventQueued, TaskEvent{ToStatus: StatusPending}.Kind())
+	assert.Equal(t, EventQueued, TaskEvent{FromStatus: StatusScheduled, ToStatus: StatusPending}.Kind())
+	assert.Equal(t, EventStarted, TaskEvent{FromStatus: StatusPending, ToStatus: StatusRunning}.Kind())
+	assert.Equal(t, EventAttemptFailed, TaskEvent{FromStatus: StatusRunning, ToStatus: StatusPending}.Kind())
+	assert.Equal(t, EventRetried, TaskEvent{FromStatus: StatusTimedOut, ToStatus: StatusPending}.Kind())
+	assert.Equal(t, EventRedriven, TaskEvent{FromStatus: StatusDeadLetter, ToStatus: StatusPending}.Kind())
+	assert.Equal(t, EventCompleted, TaskEvent{FromStatus: StatusRunning, ToStatus: StatusCompleted}.Kind())
+	assert.Equal(t, EventCancelled, TaskEvent{FromStatus: StatusPending, ToStatus: StatusCancelled}.Kind())
+}
+
+func TestEventFilter_Matches(t *testing.T) {
+	taskId := uuid.New()
+	event := TaskEvent{TaskId: taskId, FromStatus: StatusRunning, ToStatus: StatusCompleted}
+
+	assert.True(t, EventFilter{}.Matches(event))
+	assert.True(t, EventFilter{TaskId: taskId}.Matches(event))
+	assert.False(t, EventFilter{TaskId: uuid.New()}.Matches(event))
+	assert.True(t, EventFilter{Statuses: []Status{StatusFailed, StatusCompleted}}.Matches(event))
+	assert.False(t, EventFilter{TaskId: taskId, Statuses: []Status{StatusFailed}}.Matches(event))
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+)
+
+// IdempotencyKeyTTL is how long a key answers the retries of the request which first used it
+const IdempotencyKeyTTL = 24 * time.Hour
+
+var (
+	ErrIdempotencyKeyReused = &appErr.Error{
+		Cause:   errors.New("idempotency key was used with a different request"),
+		Message: "idempotency key was used with a different request",
+		Class:   appErr.EConflict,
+	}
+	ErrIdempotencyKeyInUse = &appErr.Error{
+		Cause:   errors.New("idempotency key is used by a request in progress"),
+		Message: "idempotency key is used by a request in progress",
+		Class:   appErr.EConflict,
+	}
+)
+
+// IdempotencyKey remembers the response of a create request, a retry which sends the same key and body
+// gets the same response instead of creating the task again
+type IdempotencyKey struct {
+	Key         string          `gorm:"column:key;primary_key;type:varchar(255)"`
+	CreatedAt   time.Time       `gorm:"column:created_at;not null"`
+	ExpiresAt   time.Time       `gorm:"column:expires_at;not null"`
+	RequestHash string          `gorm:"column:request_hash;type:varchar(64);not null"`
+	TaskId      uuid.UUID       `gorm:"column:task_id;type:uuid;not null"`
+	StatusCode  int             `gorm:"column:status_code;not null"`
+	Response    json.RawMessage `gorm:"column:response;type:jsonb;not null"`
+}
+
+func NewIdempotencyKey(key string, requestHash string, taskId uuid.UUID, statusCode int, response json.RawMessage) IdempotencyKey {
+	now := time.Now()
+
+	return IdempotencyKey{
+		Key:         key,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(IdempotencyKeyTTL),
+		RequestHash: requestHash,
+		TaskId:      taskId,
+		StatusCode:  statusCode,
+		Response:    response,
+	}
+}
+
+// Matches tells whether the request which sent the key again is the one the key was stored for
+func (k IdempotencyKey) Matches(requestHash string) bool {
+	return k.RequestHash == requestHash
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+type BackoffType string
+
+const (
+	BackoffFixed       BackoffType = "FIXED"
+	BackoffExponential BackoffType = "EXPONENTIAL"
+)
+
+// RetryPolicy describes how many times a task may run and how long to wait between attempts,
+// the zero value allows a single attempt
+type RetryPolicy struct {
+	MaxAttempts int           `gorm:"column:max_attempts;not null" validate:"min=0"`
+	Backoff     BackoffType   `gorm:"column:backoff;type:varchar(32);not null" validate:"omitempty,oneof=FIXED EXPONENTIAL"`
+	Delay       time.Duration `gorm:"column:backoff_delay;not null" validate:"min=0"`
+	MaxDelay    time.Duration `gorm:"column:backoff_max_delay;not null" validate:"min=0"`
+	Jitter      float64       `gorm:"column:backoff_jitter;not null" validate:"min=0,max=1"`
+}
+
+// CanRetry reports whether another attempt is allowed after the given number of attempts
+func (r RetryPolicy) CanRetry(attempts int) bool {
+	return attempts < r.MaxAttempts
+}
+
+// Retryable reports whether the policy allows more than one attempt
+func (r RetryPolicy) Retryable() bool {
+	return r.MaxAttempts > 1
+}
+
+// NextDelay returns the wait before the next attempt, attempts is the number of attempts already made.
+// The jitter spreads the delay randomly by the given fraction in both directions.
+func (r RetryPolicy) NextDelay(attempts int) time.Duration {
+	delay := float64(r.Delay)
+	if r.Backoff == BackoffExponential && attempts > 1 {
+		delay *= math.Pow(2, float64(attempts-1))
+	}
+
+	if r.MaxDelay > 0 && delay > float64(r.MaxDelay) {
+		delay = float64(r.MaxDelay)
+	}
+
+	if r.Jitter > 0 {
+		delay += delay * r.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(math.Max(delay, 0))
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_NextDelay_Fixed(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: BackoffFixed, Delay: time.Second}
+
+	assert.Equal(t, time.Second, policy.NextDelay(1))
+	assert.Equal(t, time.Second, policy.NextDelay(2))
+}
+
+func TestRetryPolicy_NextDelay_Exponential(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, Backoff: BackoffExponential, Delay: time.Second, MaxDelay: 5 * time.Second}
+
+	assert.Equal(t, time.Second, policy.NextDelay(1))
+	assert.Equal(t, 2*time.Second, policy.NextDelay(2))
+	assert.Equal(t, 4*time.Second, policy.NextDelay(3))
+	assert.Equal(t, 5*time.Second, policy.NextDelay(4))
+}
+
+func TestRetryPolicy_NextDelay_Jitter(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: BackoffFixed, Delay: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		delay := policy.NextDelay(1)
+		assert.GreaterOrEqual(t, delay, 500*time.Millisecond)
+		assert.LessOrEqual(t, delay, 1500*time.Millisecond)
+	}
+}
+
+func TestRetryPolicy_CanRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 2}
+
+	assert.True(t, policy.CanRetry(1))
+	assert.False(t, policy.CanRetry(2))
+	assert.False(t, RetryPolicy{}.CanRetry(0))
+}
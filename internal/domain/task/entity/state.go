@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"errors"
+	"slices"
+
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+)
+
+var ErrInvalidStateTransition = &appErr.Error{
+	Cause:   errors.New("invalid task state transition"),
+	Message: "the task can not move from its current status to the requested one",
+	Class:   appErr.EConflict,
+}
+
+// transitions lists the statuses each status may move to, the statuses missing from it are final.
+// A timed out run still moves on to a retry or to the dead letter state, and so does a running task
+// whose lease has expired or whose attempt failed.
+var transitions = map[Status][]Status{
+	StatusScheduled: {StatusPending, StatusCancelled},
+	StatusPending:   {StatusRunning, StatusCancelled},
+	StatusRunning: {
+		StatusCompleted,
+		StatusFailed,
+		StatusCancelled,
+		StatusTimedOut,
+		StatusDeadLetter,
+		StatusPending,
+	},
+	StatusTimedOut:   {StatusPending, StatusDeadLetter},
+	StatusDeadLetter: {StatusPending},
+}
+
+// StateColumns are the columns the state machine owns, a transition writes only them so the edits made to the
+// rest of the task while it is queued or running survive it
+var StateColumns = []string{
+	"status",
+	"attempts",
+	"error",
+	"last_error",
+	"result",
+	"queued_at",
+	"started_at",
+	"finished_at",
+	"worker_id",
+	"updated_at",
+}
+
+// IsFinal reports whether the status has no way out of it, a task in a final status never runs again
+func (s Status) IsFinal() bool {
+	return len(transitions[s]) == 0
+}
+
+// CanTransitionTo reports whether a task may move from s to the given status, staying in the same status
+// is always allowed
+func (s Status) CanTransitionTo(to Status) bool {
+	if s == to {
+		return true
+	}
+
+	return slices.Contains(transitions[s], to)
+}
+
+// TransitionTo moves the task to the given status when the state machine allows it
+func (u *Task) TransitionTo(to Status) error {
+	if !u.Status.CanTransitionTo(to) {
+		return ErrInvalidStateTransition
+	}
+
+	u.Status = to
+	return nil
+}
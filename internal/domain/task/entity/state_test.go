@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, StatusScheduled.CanTransitionTo(StatusPending))
+	assert.True(t, StatusPending.CanTransitionTo(StatusRunning))
+	assert.True(t, StatusRunning.CanTransitionTo(StatusCompleted))
+	assert.True(t, StatusRunning.CanTransitionTo(StatusPending))
+	assert.True(t, StatusTimedOut.CanTransitionTo(StatusDeadLetter))
+	assert.True(t, StatusDeadLetter.CanTransitionTo(StatusPending))
+	assert.True(t, StatusCompleted.CanTransitionTo(StatusCompleted))
+
+	assert.False(t, StatusPending.CanTransitionTo(StatusCompleted))
+	assert.False(t, StatusCompleted.CanTransitionTo(StatusRunning))
+	assert.False(t, StatusCancelled.CanTransitionTo(StatusPending))
+	assert.False(t, StatusFailed.CanTransitionTo(StatusPending))
+}
+
+func TestStatus_IsFinal(t *testing.T) {
+	assert.True(t, StatusCompleted.IsFinal())
+	assert.True(t, StatusFailed.IsFinal())
+	assert.True(t, StatusCancelled.IsFinal())
+
+	assert.False(t, StatusTimedOut.IsFinal())
+	assert.False(t, StatusDeadLetter.IsFinal())
+	assert.False(t, StatusPending.IsFinal())
+}
+
+func TestTask_TransitionTo(t *testing.T) {
+	task := Task{Status: StatusPending}
+
+	assert.NoError(t, task.TransitionTo(StatusRunning))
+	assert.Equal(t, StatusRunning, task.Status)
+
+	task.Status = StatusCompleted
+	assert.ErrorIs(t, task.TransitionTo(StatusRunning), ErrInvalidStateTransition)
+	assert.Equal(t, StatusCompleted, task.Status)
+}
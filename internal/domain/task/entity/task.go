@@ -2,18 +2,41 @@ package entity
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+	"github.com/thealiakbari/task-pool-system/pkg/common/validation"
 )
 
 type Status string
 
 const (
-	StatusPending   Status = "PENDING"
-	StatusRunning   Status = "RUNNING"
-	StatusCompleted Status = "COMPLETED"
-	StatusFailed    Status = "FAILED"
+	StatusScheduled  Status = "SCHEDULED"
+	StatusPending    Status = "PENDING"
+	StatusRunning    Status = "RUNNING"
+	StatusCompleted  Status = "COMPLETED"
+	StatusFailed     Status = "FAILED"
+	StatusCancelled  Status = "CANCELLED"
+	StatusDeadLetter Status = "DEAD_LETTER"
+	StatusTimedOut   Status = "TIMED_OUT"
+)
+
+const (
+	MinPriority     = 0
+	DefaultPriority = 5
+	MaxPriority     = 9
+
+	// PriorityAging is the waiting time which is worth one priority level, it keeps low priority work from starving
+	PriorityAging = 30 * time.Second
+)
+
+// MaxPayloadSize and MaxResultSize bound the JSON documents stored with a task, in bytes
+const (
+	MaxPayloadSize = 64 << 10
+	MaxResultSize  = 256 << 10
 )
 
 type Task struct {
@@ -21,9 +44,92 @@ type Task struct {
 	Title       string `gorm:"column:title;type:varchar(255);not null" validate:"required"`
 	Description string `gorm:"column:description;type:text;not null" validate:"required"`
 	Status      Status
+	Type        string          `gorm:"column:type;type:varchar(64);not null"`
+	Payload     json.RawMessage `gorm:"column:payload;type:jsonb" validate:"max=65536"`
+	Result      json.RawMessage `gorm:"column:result;type:jsonb" validate:"max=262144"`
+	Error       string          `gorm:"column:error;type:text;not null"`
 	Duration    time.Duration
+	Timeout     time.Duration `gorm:"column:timeout;not null" validate:"min=0"`
+	Priority    int           `gorm:"column:priority;not null" validate:"min=0,max=9"`
+	Retry       RetryPolicy   `gorm:"embedded"`
+	RunAt       *time.Time    `gorm:"column:run_at"`
+	Attempts    int           `gorm:"column:attempts;not null"`
+	LastError   string        `gorm:"column:last_error;type:text;not null"`
+	QueuedAt    *time.Time    `gorm:"column:queued_at"`
+	StartedAt   *time.Time    `gorm:"column:started_at"`
+	FinishedAt  *time.Time    `gorm:"column:finished_at"`
+	WorkerId    string        `gorm:"column:worker_id;type:varchar(255);not null"`
+	// Leases are only written by the dedicated repository methods, so saving a task never steals or drops one
+	LeaseOwner     string     `gorm:"column:lease_owner;type:varchar(255);->"`
+	LeaseExpiresAt *time.Time `gorm:"column:lease_expires_at;->"`
+}
+
+// IsSettled reports whether the task rests in a status it does not leave on its own: a final one, the dead
+// letter state or a timeout which is not retried. Only a re-drive moves a settled task again.
+func (u Task) IsSettled() bool {
+	switch {
+	case u.Status.IsFinal(), u.Status == StatusDeadLetter:
+		return true
+	case u.Status == StatusTimedOut:
+		return !u.Retry.Retryable()
+	default:
+		return false
+	}
+}
+
+// SettlesIn reports whether the task would be settled in the given status, its retry policy decides for a timeout
+func (u Task) SettlesIn(status Status) bool {
+	u.Status = status
+	return u.IsSettled()
+}
+
+// WaitTime is the time the last attempt waited in the queue before a worker started it, zero while it waits
+func (u Task) WaitTime() time.Duration {
+	if u.QueuedAt == nil || u.StartedAt == nil || u.StartedAt.Before(*u.QueuedAt) {
+		return 0
+	}
+
+	return u.StartedAt.Sub(*u.QueuedAt)
+}
+
+// RunTime is the time the last attempt ran, an attempt which is still running reports the time so far
+func (u Task) RunTime(now time.Time) time.Duration {
+	if u.StartedAt == nil {
+		return 0
+	}
+
+	if u.FinishedAt != nil && !u.FinishedAt.Before(*u.StartedAt) {
+		return u.FinishedAt.Sub(*u.StartedAt)
+	}
+
+	if u.Status == StatusRunning {
+		return now.Sub(*u.StartedAt)
+	}
+
+	return 0
 }
 
 func (u Task) Validate(ctx context.Context) error {
+	if err := validation.Validate(ctx, u); err != nil {
+		return &appErr.Error{
+			Cause:   err,
+			Message: err.Error(),
+			Class:   appErr.EValidation,
+		}
+	}
+
 	return nil
 }
+
+// TaskFilter holds the criteria used to list tasks, every empty field is ignored
+type TaskFilter struct {
+	Ids       []string
+	Titles    []string
+	Statuses  []Status
+	Types     []string
+	CreatedAt request.DateRange
+	UpdatedAt request.DateRange
+	Duration  request.NumberRange
+	SortBy    string
+	SortType  request.SortType
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTask_WaitAndRunTime(t *testing.T) {
+	queuedAt := time.Now().Add(-3 * time.Second)
+	startedAt := queuedAt.Add(time.Second)
+	finishedAt := startedAt.Add(500 * time.Millisecond)
+
+	task := Task{Status: StatusCompleted, QueuedAt: &queuedAt, StartedAt: &startedAt, FinishedAt: &finishedAt}
+	assert.Equal(t, time.Second, task.WaitTime())
+	assert.Equal(t, 500*time.Millisecond, task.RunTime(time.Now()))
+}
+
+func TestTask_RunTimeWhileRunning(t *testing.T) {
+	startedAt := time.Now().Add(-time.Second)
+	task := Task{Status: StatusRunning, StartedAt: &startedAt}
+
+	assert.Equal(t, time.Second, task.RunTime(startedAt.Add(time.Second)))
+}
+
+func TestTask_WaitTimeOfQueuedRetry(t *testing.T) {
+	startedAt := time.Now().Add(-time.Second)
+	queuedAt := time.Now().Add(time.Second)
+	task := Task{Status: StatusPending, QueuedAt: &queuedAt, StartedAt: &startedAt, FinishedAt: &startedAt}
+
+	assert.Zero(t, task.WaitTime())
+}
+
+func TestTask_IsSettled(t *testing.T) {
+	assert.True(t, Task{Status: StatusCompleted}.IsSettled())
+	assert.True(t, Task{Status: StatusDeadLetter}.IsSettled())
+	assert.True(t, Task{Status: StatusTimedOut}.IsSettled())
+
+	assert.False(t, Task{Status: StatusTimedOut, Retry: RetryPolicy{MaxAttempts: 3}}.IsSettled())
+	assert.False(t, Task{Status: StatusPending}.IsSettled())
+	assert.False(t, Task{Status: StatusRunning}.IsSettled())
+}
+
+func TestTask_SettlesIn(t *testing.T) {
+	retried := Task{Status: StatusRunning, Retry: RetryPolicy{MaxAttempts: 3}}
+	assert.False(t, retried.SettlesIn(StatusTimedOut))
+	assert.True(t, retried.SettlesIn(StatusDeadLetter))
+	assert.True(t, Task{Status: StatusRunning}.SettlesIn(StatusTimedOut))
+	assert.Equal(t, StatusRunning, retried.Status)
+}
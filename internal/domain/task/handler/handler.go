@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrUnknownType      = errors.New("no handler is registered for the task type")
+	ErrDuplicateHandler = errors.New("a handler is already registered for the task type")
+)
+
+// Handler does the work of one task type. It receives the context of the run, which is cancelled when
+// the task is cancelled or the pool shuts down, and the payload of the task. The returned result is
+// recorded with the task when the handler succeeds.
+type Handler interface {
+	Handle(ctx context.Context, payload json.RawMessage) (result json.RawMessage, err error)
+}
+
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error)
+
+func (f HandlerFunc) Handle(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+	return f(ctx, payload)
+}
+
+// Registry maps the task types to their handlers, it is safe for concurrent use
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+func (r *Registry) Register(taskType string, h Handler) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.handlers[taskType]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateHandler, taskType)
+	}
+
+	r.handlers[taskType] = h
+	return nil
+}
+
+// MustRegister registers the handler and panics when the type is taken, it is meant for the setup
+func (r *Registry) MustRegister(taskType string, h Handler) {
+	if err := r.Register(taskType, h); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Registry) Get(taskType string) (Handler, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	h, ok := r.handlers[taskType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, taskType)
+	}
+
+	return h, nil
+}
+
+func (r *Registry) Has(taskType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.handlers[taskType]
+	return ok
+}
+
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.handlers))
+	for taskType := range r.handlers {
+		types = append(types, taskType)
+	}
+	sort.Strings(types)
+
+	return types
+}
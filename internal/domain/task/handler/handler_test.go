@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_RegisterAndGet(t *testing.T) {
+	r := NewRegistry()
+	assert.NoError(t, r.Register(TypeSleep, Sleep()))
+	assert.ErrorIs(t, r.Register(TypeSleep, Sleep()), ErrDuplicateHandler)
+
+	_, err := r.Get(TypeSleep)
+	assert.NoError(t, err)
+
+	_, err = r.Get("email")
+	assert.ErrorIs(t, err, ErrUnknownType)
+	assert.Equal(t, []string{TypeSleep}, r.Types())
+}
+
+func TestSleep_ReturnsAfterDuration(t *testing.T) {
+	result, err := Sleep().Handle(context.Background(), NewSleepPayload(time.Millisecond))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"slept":"1ms"}`, string(result))
+}
+
+func TestSleep_StopsOnCancel(t *testing.T) {
+	cause := errors.New("stopped")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+
+	_, err := Sleep().Handle(ctx, NewSleepPayload(time.Minute))
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestSleep_InvalidPayload(t *testing.T) {
+	_, err := Sleep().Handle(context.Background(), []byte(`{"duration":"soon"}`))
+	assert.Error(t, err)
+
+	_, err = ParseSleepPayload([]byte(`{"duration":"-1s"}`))
+	assert.Error(t, err)
+}
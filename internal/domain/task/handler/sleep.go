@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TypeSleep is the built-in task type which only waits, it simulates work of the given duration
+const TypeSleep = "sleep"
+
+type SleepPayload struct {
+	Duration string `json:"duration"`
+}
+
+func NewSleepPayload(duration time.Duration) json.RawMessage {
+	payload, _ := json.Marshal(SleepPayload{Duration: duration.String()})
+	return payload
+}
+
+// ParseSleepPayload returns the duration of a sleep payload
+func ParseSleepPayload(payload json.RawMessage) (time.Duration, error) {
+	var in SleepPayload
+	if err := json.Unmarshal(payload, &in); err != nil {
+		return 0, fmt.Errorf("invalid sleep payload: %w", err)
+	}
+
+	duration, err := time.ParseDuration(in.Duration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sleep duration: %w", err)
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("invalid sleep duration: %s is negative", in.Duration)
+	}
+
+	return duration, nil
+}
+
+func Sleep() Handler {
+	return HandlerFunc(func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+		duration, err := ParseSleepPayload(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-time.After(duration):
+			return json.RawMessage(fmt.Sprintf(`{"slept":%q}`, duration)), nil
+		}
+	})
+}
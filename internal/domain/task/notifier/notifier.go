@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+)
+
+var ErrNotifierClosed = errors.New("notifier is closed")
+
+type Deps struct {
+	TaskService task.TaskService
+}
+
+// Notifier tells the watchers of a task about the status changes of the task, all the watchers of a task share
+// a single entry. It is fed with the events this process records, the tasks other executors move are only seen
+// when an interval is set: the watched tasks are then read in a single query every interval.
+type Notifier struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	deps     Deps
+	interval time.Duration
+
+	entries map[uuid.UUID]*entry
+	closed  bool
+
+	mu sync.Mutex
+}
+
+type entry struct {
+	// changed is closed and replaced on every change of the task
+	changed  chan struct{}
+	last     entity.Status
+	err      error
+	watchers int
+}
+
+// Watch follows the status changes of a task until it is stopped or the notifier is closed
+type Watch struct {
+	id       uuid.UUID
+	entry    *entry
+	notifier *Notifier
+}
+
+// New returns a notifier fed by Publish alone, a positive interval makes Start read the watched tasks as well
+func New(parent context.Context, interval time.Duration) *Notifier {
+	ctx, cancel := context.WithCancel(parent)
+
+	return &Notifier{
+		ctx:      ctx,
+		cancel:   cancel,
+		interval: interval,
+		entries:  make(map[uuid.UUID]*entry),
+	}
+}
+
+// Start reads the watched tasks every interval until the notifier is closed, it does nothing without an interval
+func (n *Notifier) Start(deps Deps) {
+	n.deps = deps
+	if n.interval <= 0 {
+		return
+	}
+
+	n.wg.Add(1)
+	go n.poll()
+}
+
+// Watch starts watching the task, it must be called before the task is read so no change can be missed
+// and the watch must be stopped
+func (n *Notifier) Watch(id uuid.UUID) *Watch {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	e, ok := n.entries[id]
+	if !ok {
+		e = &entry{changed: make(chan struct{})}
+		if n.closed {
+			e.err = ErrNotifierClosed
+			close(e.changed)
+		} else {
+			n.entries[id] = e
+		}
+	}
+	e.watchers++
+
+	return &Watch{id: id, entry: e, notifier: n}
+}
+
+// Next returns the last status the task moved to since the watch started, empty while it has not moved, and a
+// channel which is closed on the next change. err is ErrNotifierClosed once no change is told any more.
+func (w *Watch) Next() (changed <-chan struct{}, last entity.Status, err error) {
+	w.notifier.mu.Lock()
+	defer w.notifier.mu.Unlock()
+
+	return w.entry.changed, w.entry.last, w.entry.err
+}
+
+func (w *Watch) Stop() {
+	w.notifier.mu.Lock()
+	defer w.notifier.mu.Unlock()
+
+	w.entry.watchers--
+	if w.entry.watchers == 0 && w.notifier.entries[w.id] == w.entry {
+		delete(w.notifier.entries, w.id)
+	}
+}
+
+// Publish tells the watchers of the task the status the event moves it to
+func (n *Notifier) Publish(event entity.TaskEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.change(event.TaskId, event.ToStatus)
+}
+
+// Close ends every watch, the watchers fall back to reading the task and no new watch waits
+func (n *Notifier) Close() {
+	n.cancel()
+	n.wg.Wait()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.closed = true
+	for id, e := range n.entries {
+		e.err = ErrNotifierClosed
+		close(e.changed)
+		delete(n.entries, id)
+	}
+}
+
+func (n *Notifier) poll() {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			n.check()
+		}
+	}
+}
+
+// check reads every watched task at once and tells the watchers of the settled ones
+func (n *Notifier) check() {
+	ids := n.watched()
+	if len(ids) == 0 {
+		return
+	}
+
+	tasks, _, err := n.deps.TaskService.List(n.ctx, entity.TaskFilter{Ids: ids}, request.Portion{Limit: len(ids)})
+	if err != nil {
+		log.Printf("[NOTIFIER] cannot read the watched tasks: %v", err)
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, task := range tasks {
+		if task.IsSettled() {
+			n.change(task.Id, task.Status)
+		}
+	}
+}
+
+func (n *Notifier) watched() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ids := make([]string, 0, len(n.entries))
+	for id := range n.entries {
+		ids = append(ids, id.String())
+	}
+
+	return ids
+}
+
+// change wakes the watchers of the task, it must be called with the lock held
+func (n *Notifier) change(id uuid.UUID, status entity.Status) {
+	e, ok := n.entries[id]
+	if !ok || e.last == status {
+		return
+	}
+
+	e.last = status
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
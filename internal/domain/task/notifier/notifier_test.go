@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+)
+
+// fakeTaskService serves the watched tasks from memory
+type fakeTaskService struct {
+	task.TaskService
+	tasks []entity.Task
+}
+
+func (f *fakeTaskService) List(ctx context.Context, filter entity.TaskFilter, portion request.Portion) ([]entity.Task, int64, error) {
+	return f.tasks, int64(len(f.tasks)), nil
+}
+
+func newTask(status entity.Status) entity.Task {
+	task := entity.Task{Title: "test", Status: status}
+	task.Id = uuid.New()
+	return task
+}
+
+func newEvent(task entity.Task, from entity.Status) entity.TaskEvent {
+	return entity.NewTaskEvent(task, from, entity.Transition{})
+}
+
+// changed reports whether the watch was told a change since changed was taken from it
+func changed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestPublish_TellsEveryWatcher(t *testing.T) {
+	n := New(context.Background(), 0)
+	task := newTask(entity.StatusRunning)
+	first, second := n.Watch(task.Id), n.Watch(task.Id)
+	defer first.Stop()
+	defer second.Stop()
+
+	before, last, err := first.Next()
+	assert.Empty(t, last)
+	assert.NoError(t, err)
+
+	n.Publish(newEvent(task, entity.StatusPending))
+	assert.True(t, changed(before))
+
+	task.Status = entity.StatusCancelled
+	n.Publish(newEvent(task, entity.StatusRunning))
+	for _, watch := range []*Watch{first, second} {
+		next, last, err := watch.Next()
+		assert.Equal(t, entity.StatusCancelled, last)
+		assert.NoError(t, err)
+		assert.False(t, changed(next))
+	}
+}
+
+func TestPublish_IgnoresOtherTasks(t *testing.T) {
+	n := New(context.Background(), 0)
+	watched, other := newTask(entity.StatusCompleted), newTask(entity.StatusCompleted)
+	watch := n.Watch(watched.Id)
+	defer watch.Stop()
+
+	next, _, _ := watch.Next()
+	n.Publish(newEvent(other, entity.StatusRunning))
+	assert.False(t, changed(next), "watch is told about another task")
+}
+
+func TestCheck_TellsTasksSettledElsewhere(t *testing.T) {
+	n := New(context.Background(), time.Hour)
+	running, completed := newTask(entity.StatusRunning), newTask(entity.StatusCompleted)
+	n.deps = Deps{TaskService: &fakeTaskService{tasks: []entity.Task{running, completed}}}
+
+	waiting, done := n.Watch(running.Id), n.Watch(completed.Id)
+	defer waiting.Stop()
+	defer done.Stop()
+
+	n.check()
+	_, last, _ := done.Next()
+	assert.Equal(t, entity.StatusCompleted, last)
+	_, last, _ = waiting.Next()
+	assert.Empty(t, last)
+}
+
+func TestStart_PollsWatchedTasks(t *testing.T) {
+	n := New(context.Background(), 10*time.Millisecond)
+	completed := newTask(entity.StatusCompleted)
+	n.Start(Deps{TaskService: &fakeTaskService{tasks: []entity.Task{completed}}})
+	defer n.Close()
+
+	watch := n.Watch(completed.Id)
+	defer watch.Stop()
+
+	next, _, _ := watch.Next()
+	select {
+	case <-next:
+	case <-time.After(time.Second):
+		t.Fatal("the watched task is not read")
+	}
+}
+
+func TestStart_DoesNotPollWithoutInterval(t *testing.T) {
+	n := New(context.Background(), 0)
+	completed := newTask(entity.StatusCompleted)
+	svc := &fakeTaskService{tasks: []entity.Task{completed}}
+	n.Start(Deps{TaskService: svc})
+	defer n.Close()
+
+	watch := n.Watch(completed.Id)
+	defer watch.Stop()
+
+	next, _, _ := watch.Next()
+	select {
+	case <-next:
+		t.Fatal("the watched task is read without an interval")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestStop_ForgetsTheTask(t *testing.T) {
+	n := New(context.Background(), 0)
+	task := newTask(entity.StatusRunning)
+	first, second := n.Watch(task.Id), n.Watch(task.Id)
+
+	first.Stop()
+	assert.Len(t, n.entries, 1)
+	second.Stop()
+	assert.Empty(t, n.entries)
+}
+
+func TestClose_EndsWatches(t *testing.T) {
+	n := New(context.Background(), 0)
+	task := newTask(entity.StatusRunning)
+	watch := n.Watch(task.Id)
+	defer watch.Stop()
+
+	next, _, _ := watch.Next()
+	n.Close()
+	<-next
+	_, _, err := watch.Next()
+	assert.ErrorIs(t, err, ErrNotifierClosed)
+
+	late := n.Watch(task.Id)
+	defer late.Stop()
+	next, _, err = late.Next()
+	<-next
+	assert.ErrorIs(t, err, ErrNotifierClosed)
+}
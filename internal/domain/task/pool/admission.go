@@ -0,0 +1,127 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultSubmitTimeout = 5 * time.Second
+	minRetryAfter        = time.Second
+	maxRetryAfter        = time.Minute
+)
+
+var ErrInvalidSubmitPolicy = errors.New("submit policy must be one of reject, block or backlog")
+
+// SubmitPolicy decides what happens to a new task while the queue of the pool is full
+type SubmitPolicy string
+
+const (
+	// PolicyReject refuses the task at once
+	PolicyReject SubmitPolicy = "reject"
+	// PolicyBlock waits for room in the queue until the submit timeout and refuses the task after it
+	PolicyBlock SubmitPolicy = "block"
+	// PolicyBacklog accepts the task into the durable backlog, the pool claims it once the queue has room
+	PolicyBacklog SubmitPolicy = "backlog"
+)
+
+func ParseSubmitPolicy(policy string) (SubmitPolicy, error) {
+	switch SubmitPolicy(policy) {
+	case PolicyReject, PolicyBlock, PolicyBacklog:
+		return SubmitPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidSubmitPolicy, policy)
+	}
+}
+
+// SetSubmitPolicy sets how Admit treats a full queue, a zero timeout keeps the current one
+func (p *Pool) SetSubmitPolicy(policy SubmitPolicy, timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.policy = policy
+	if timeout > 0 {
+		p.submitTimeout = timeout
+	}
+}
+
+// Admit tells whether the pool takes a new task, it is asked before the task is stored so a refused task
+// leaves nothing behind. A draining or stopped pool refuses every task, a full queue is handled by the
+// submit policy.
+func (p *Pool) Admit(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var deadline <-chan time.Time
+	if p.policy == PolicyBlock {
+		timer := time.NewTimer(p.submitTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		switch {
+		case p.ctx.Err() != nil:
+			return ErrPoolStopped
+		case p.draining:
+			return ErrPoolDraining
+		case p.policy == PolicyBacklog, p.queue.Len() < p.size:
+			return nil
+		case p.policy != PolicyBlock:
+			return ErrPoolFull
+		}
+
+		if p.room == nil {
+			p.room = make(chan struct{})
+		}
+		room := p.room
+
+		p.mu.Unlock()
+		select {
+		case <-room:
+			p.mu.Lock()
+		case <-deadline:
+			p.mu.Lock()
+			return ErrPoolFull
+		case <-ctx.Done():
+			p.mu.Lock()
+			return ctx.Err()
+		case <-p.ctx.Done():
+			p.mu.Lock()
+			return ErrPoolStopped
+		}
+	}
+}
+
+// RetryAfter estimates when a refused task may be submitted again from the throughput of the last minute,
+// a full queue has room once a task is done while a draining pool has to finish every task it holds
+func (p *Pool) RetryAfter() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rate := p.meter.rate(time.Now())
+	if rate == 0 {
+		return maxRetryAfter
+	}
+
+	waiting := 1
+	if p.draining {
+		waiting = max(p.queue.Len()+len(p.running), 1)
+	}
+	seconds := math.Ceil(float64(waiting) / rate)
+
+	return min(max(time.Duration(seconds)*time.Second, minRetryAfter), maxRetryAfter)
+}
+
+// freeRoom wakes up the submissions waiting for room in the queue, it must be called while holding the lock
+func (p *Pool) freeRoom() {
+	if p.room == nil {
+		return
+	}
+
+	close(p.room)
+	p.room = nil
+}
@@ -0,0 +1,102 @@
+package pool
+
+import (
+	"context"
+	"log"
+)
+
+// Pause stops the workers from taking queued tasks, the running tasks finish and the pool keeps accepting
+// submissions until Resume is called
+func (p *Pool) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resumed != nil {
+		return
+	}
+
+	p.resumed = make(chan struct{})
+	log.Printf("[POOL] paused with %d queued tasks", p.queue.Len())
+}
+
+// Resume restarts the dispatch of a paused pool and opens a draining pool to submissions again
+func (p *Pool) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.resume()
+	if p.draining {
+		p.draining = false
+		log.Println("[POOL] accepting tasks again")
+	}
+}
+
+// resume must be called while holding the lock
+func (p *Pool) resume() {
+	if p.resumed == nil {
+		return
+	}
+
+	close(p.resumed)
+	p.resumed = nil
+	log.Printf("[POOL] resumed with %d queued tasks", p.queue.Len())
+}
+
+// Drain stops the pool from accepting tasks and waits until every queued and running task is done or
+// the context ends. The pool keeps refusing tasks after the drain until Resume is called, the retries
+// which come due in the meantime are left to the durable backlog.
+func (p *Pool) Drain(ctx context.Context) error {
+	p.mu.Lock()
+	p.resume()
+	if !p.draining {
+		p.draining = true
+		log.Printf("[POOL] draining %d queued and %d running tasks", p.queue.Len(), len(p.running))
+	}
+	if p.drained == nil {
+		p.drained = make(chan struct{})
+	}
+	drained := p.drained
+	p.settle()
+	p.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatch returns the channel a worker takes tasks from, or the channel which is closed on resume
+// while the pool is paused
+func (p *Pool) dispatch() (<-chan struct{}, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resumed != nil {
+		return nil, p.resumed
+	}
+
+	return p.ready, nil
+}
+
+// settle wakes up the waiters of Stop once no task is running and the waiters of Drain once the pool holds
+// no work, it must be called while holding the lock
+func (p *Pool) settle() {
+	if len(p.running) > 0 {
+		return
+	}
+
+	if p.idle != nil {
+		close(p.idle)
+		p.idle = nil
+	}
+
+	if !p.draining || p.drained == nil || p.queue.Len() > 0 {
+		return
+	}
+
+	close(p.drained)
+	p.drained = nil
+	log.Println("[POOL] drained")
+}
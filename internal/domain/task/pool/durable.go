@@ -0,0 +1,177 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+)
+
+const (
+	defaultLeaseTTL     = 30 * time.Second
+	defaultPollInterval = 2 * time.Second
+	defaultStallTimeout = time.Minute
+)
+
+// newOwner names the pool in the lease columns, it is unique per process
+func newOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
+}
+
+// feed moves the running tasks of dead pools back to pending and claims pending tasks from the
+// database whenever the queue has room, so accepted work survives restarts and full queues
+func (p *Pool) feed() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.reclaim()
+		p.claim()
+		p.fed()
+
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fed records that the feed went round, Live fails once it has not for the stall timeout
+func (p *Pool) fed() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.fedAt = time.Now()
+}
+
+func (p *Pool) reclaim() {
+	_, _ = p.deps.TaskService.ReclaimExpired(p.ctx)
+}
+
+func (p *Pool) claim() {
+	p.mu.Lock()
+	free := p.size - p.queue.Len()
+	if p.draining {
+		free = 0
+	}
+	p.mu.Unlock()
+
+	if free <= 0 {
+		return
+	}
+
+	tasks, err := p.deps.TaskService.ClaimPending(p.ctx, p.owner, free, p.leaseTTL)
+	if err != nil || len(tasks) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range tasks {
+		if err := p.enqueue(&tasks[i]); err != nil {
+			log.Printf("[POOL] claimed task %s is left to the durable backlog: %v", tasks[i].Id, err)
+		}
+	}
+	log.Printf("[POOL] claimed %d pending tasks", len(tasks))
+}
+
+// renew keeps the leases of every task held by the pool alive and stops the held tasks which were cancelled
+// through another executor
+func (p *Pool) renew() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.leaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			held := p.held()
+			_ = p.deps.TaskService.RenewLeases(p.ctx, p.owner, held, p.leaseTTL)
+			p.cancelStored(held)
+		}
+	}
+}
+
+// cancelStored cancels the held tasks whose stored status is cancelled, a cancel request only stops the task
+// right away on the executor which served it
+func (p *Pool) cancelStored(held []uuid.UUID) {
+	if len(held) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(held))
+	for _, id := range held {
+		ids = append(ids, id.String())
+	}
+
+	filter := entity.TaskFilter{Ids: ids, Statuses: []entity.Status{entity.StatusCancelled}}
+	tasks, _, err := p.deps.TaskService.List(p.ctx, filter, request.Portion{Limit: len(ids)})
+	if err != nil {
+		log.Printf("[POOL] cannot look up the cancelled tasks: %v", err)
+		return
+	}
+
+	for _, task := range tasks {
+		if p.Cancel(task.Id) {
+			log.Printf("[POOL] task %s was cancelled through another executor", task.Id)
+		}
+	}
+}
+
+// lease makes sure no other pool runs the task, the queue only holds hints of the durable state
+func (p *Pool) lease(task entity.Task) bool {
+	ok, err := p.deps.TaskService.Lease(context.Background(), task.Id, p.owner, p.leaseTTL)
+	if err != nil || !ok {
+		log.Printf("[POOL] task %s is not available for this pool, skipped", task.Id)
+		return false
+	}
+
+	return true
+}
+
+// holds must be called while holding the lock
+func (p *Pool) holds(id uuid.UUID) bool {
+	if _, ok := p.running[id]; ok {
+		return true
+	}
+
+	if _, ok := p.retrying[id]; ok {
+		return true
+	}
+
+	return p.queue.find(id) != nil
+}
+
+func (p *Pool) held() []uuid.UUID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := p.queue.ids()
+
+	for id := range p.running {
+		ids = append(ids, id)
+	}
+
+	for id := range p.retrying {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
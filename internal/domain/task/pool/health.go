@@ -0,0 +1,55 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrPoolNotStarted = errors.New("task pool is not started")
+	ErrPoolPaused     = errors.New("task pool is paused")
+	ErrPoolSaturated  = errors.New("task pool is saturated, the queue is full and every worker is busy")
+	ErrPoolStalled    = errors.New("task pool is stalled, it has not polled the durable backlog in time")
+)
+
+// Live fails once the pool is stopped or its feed has not gone round within the stall timeout, a feed stuck
+// on the database leaves the durable backlog and the tasks of dead pools behind
+func (p *Pool) Live(ctx context.Context) error {
+	if p.ctx.Err() != nil {
+		return ErrPoolStopped
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.launched && time.Since(p.fedAt) > p.stallTimeout {
+		return ErrPoolStalled
+	}
+
+	return nil
+}
+
+// Ready fails while the pool cannot take new tasks in time: before it starts, once it stops, while it is
+// paused or draining and while its queue is full with every worker busy
+func (p *Pool) Ready(ctx context.Context) error {
+	if p.ctx.Err() != nil {
+		return ErrPoolStopped
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case !p.launched:
+		return ErrPoolNotStarted
+	case p.resumed != nil:
+		return ErrPoolPaused
+	case p.draining:
+		return ErrPoolDraining
+	case p.queue.Len() >= p.size && len(p.running) >= p.live:
+		return ErrPoolSaturated
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package pool
+
+import "github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+
+// Observer is told when a worker starts a task and when the attempt ends with the status it was saved as,
+// it is called on the worker and must not block
+type Observer interface {
+	TaskStarted(task entity.Task)
+	TaskFinished(task entity.Task)
+}
+
+func (p *Pool) started(task entity.Task) {
+	for _, observer := range p.deps.Observers {
+		observer.TaskStarted(task)
+	}
+}
+
+func (p *Pool) finished(task entity.Task) {
+	for _, observer := range p.deps.Observers {
+		observer.TaskFinished(task)
+	}
+}
@@ -0,0 +1,61 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/thealiakbari/task-pool-system/pkg/common/logger"
+)
+
+const (
+	// panicStackSkip skips the frames of the stack capture, the recovering function and runtime.gopanic
+	panicStackSkip  = 6
+	panicStackDepth = 16
+)
+
+var ErrTaskPanicked = errors.New("task panicked")
+
+// panicError keeps the value and the stack of a recovered panic, it is stored as the error of the task
+type panicError struct {
+	value any
+	stack []string
+}
+
+// recovered must be called by the deferred function which recovered the panic
+func recovered(value any) *panicError {
+	return &panicError{
+		value: value,
+		stack: logger.Stacks(panicStackSkip, panicStackDepth),
+	}
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("%v: %v\n%s", ErrTaskPanicked, e.value, strings.Join(e.stack, "\n"))
+}
+
+func (e *panicError) Unwrap() error {
+	return ErrTaskPanicked
+}
+
+// panicked counts a recovered panic, it must be called while holding the lock
+func (p *Pool) panicked(err *panicError) {
+	p.panics++
+	log.Printf("[POOL] recovered from a panic: %v", err)
+}
+
+// replace starts a new worker in place of a worker which left after a panic
+func (p *Pool) replace(id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	p.nextWorkerID++
+	p.wg.Add(1)
+	go p.worker(p.nextWorkerID, p.deps)
+	log.Printf("[WORKER-%d] replaced by worker %d after a panic", id, p.nextWorkerID)
+}
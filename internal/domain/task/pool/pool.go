@@ -2,23 +2,72 @@ package pool
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
 	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
-	"github.com/thealiakbari/task-pool-system/pkg/common/db"
 	"log"
 	"sync"
 	"time"
 )
 
-var ErrPoolFull = errors.New("task pool is full")
+var (
+	ErrPoolFull      = errors.New("task pool is full")
+	ErrPoolDraining  = errors.New("task pool is draining and does not accept tasks")
+	ErrPoolStopped   = errors.New("task pool is stopped")
+	ErrTaskCancelled = errors.New("task cancelled")
+	ErrTaskTimedOut  = errors.New("task timed out")
+	ErrInvalidResult = errors.New("task result is not valid JSON")
+	ErrResultTooBig  = fmt.Errorf("task result is larger than %d bytes", entity.MaxResultSize)
+)
 
 type Pool struct {
-	ctx     context.Context
-	cancel  context.CancelFunc
-	queue   chan *entity.Task
-	workers int
-	wg      sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	queue    *taskQueue
+	size     int
+	ready    chan struct{}
+	running  map[uuid.UUID]context.CancelCauseFunc
+	retrying map[uuid.UUID]*time.Timer
+	wg       sync.WaitGroup
+	deps     WorkerDeps
+
+	minWorkers    int
+	maxWorkers    int
+	target        int
+	live          int
+	nextWorkerID  int
+	retire        chan struct{}
+	idleTimeout   time.Duration
+	idleSince     time.Time
+	scaleInterval time.Duration
+
+	owner        string
+	leaseTTL     time.Duration
+	pollInterval time.Duration
+	stallTimeout time.Duration
+	fedAt        time.Time
+
+	defaultTimeout time.Duration
+
+	// resumed is closed when a paused pool resumes dispatching, it is nil while the pool is not paused
+	resumed  chan struct{}
+	draining bool
+	drained  chan struct{}
+	idle     chan struct{}
+	meter    meter
+
+	policy        SubmitPolicy
+	submitTimeout time.Duration
+	// room is closed once the queue shrinks, it is nil while no submission waits for room
+	room chan struct{}
+
+	panics   int64
+	launched bool
 }
 
 func New(
@@ -29,40 +78,174 @@ func New(
 	ctx, cancel := context.WithCancel(parent)
 
 	return &Pool{
-		ctx:     ctx,
-		cancel:  cancel,
-		queue:   make(chan *entity.Task, poolSize),
-		workers: workers,
+		ctx:      ctx,
+		cancel:   cancel,
+		queue:    newTaskQueue(poolSize),
+		size:     poolSize,
+		ready:    make(chan struct{}, poolSize),
+		running:  make(map[uuid.UUID]context.CancelCauseFunc),
+		retrying: make(map[uuid.UUID]*time.Timer),
+
+		minWorkers:    workers,
+		maxWorkers:    workers,
+		target:        workers,
+		idleTimeout:   defaultIdleTimeout,
+		scaleInterval: defaultScaleInterval,
+
+		owner:        newOwner(),
+		leaseTTL:     defaultLeaseTTL,
+		pollInterval: defaultPollInterval,
+		stallTimeout: defaultStallTimeout,
+
+		policy:        PolicyBacklog,
+		submitTimeout: defaultSubmitTimeout,
 	}
 }
 
 type WorkerDeps struct {
 	TaskService task.TaskService
+	Handlers    *handler.Registry
+	Observers   []Observer
+}
+
+// SetDefaultTimeout bounds the run of the tasks which do not set their own timeout, it must be called before Start
+func (p *Pool) SetDefaultTimeout(timeout time.Duration) {
+	p.defaultTimeout = timeout
 }
 
 func (p *Pool) Start(deps WorkerDeps) {
-	log.Printf("[POOL] starting %d workers", p.workers)
+	log.Printf("[POOL] starting %d workers as %s", p.target, p.owner)
+	p.deps = deps
+	p.retire = make(chan struct{}, p.maxWorkers)
+
+	p.wg.Add(2)
+	go p.feed()
+	go p.renew()
 
-	for i := 0; i < p.workers; i++ {
+	if p.maxWorkers > p.minWorkers {
 		p.wg.Add(1)
-		go p.worker(i+1, deps)
+		go p.autoscale()
 	}
+
+	p.mu.Lock()
+	p.launched = true
+	p.fedAt = time.Now()
+	p.resize(p.target)
+	p.mu.Unlock()
 }
 
 func (p *Pool) Submit(task *entity.Task) error {
-	select {
-	case p.queue <- task:
-		log.Printf("[POOL] task submitted: %s", task.Id)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.enqueue(task)
+}
+
+// enqueue must be called while holding the lock
+func (p *Pool) enqueue(task *entity.Task) error {
+	if p.holds(task.Id) {
 		return nil
-	default:
+	}
+
+	if p.draining {
+		return ErrPoolDraining
+	}
+
+	if p.queue.Len() >= p.size {
 		return ErrPoolFull
 	}
+
+	p.queue.push(task)
+	p.signal()
+
+	log.Printf("[POOL] task submitted: %s", task.Id)
+	return nil
+}
+
+// signal wakes up a worker, it must be called while holding the lock.
+// Every queued task owns a ready signal, a full channel means there are already enough signals.
+func (p *Pool) signal() {
+	select {
+	case p.ready <- struct{}{}:
+	default:
+	}
 }
 
+// Cancel removes the task from the queue or the retry backlog, or interrupts the worker which
+// is running it, it reports false when the task is not held by the pool
+func (p *Pool) Cancel(id uuid.UUID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if timer, ok := p.retrying[id]; ok {
+		timer.Stop()
+		delete(p.retrying, id)
+		log.Printf("[POOL] pending retry cancelled: %s", id)
+		return true
+	}
+
+	if p.queue.remove(id) {
+		log.Printf("[POOL] task removed from queue: %s", id)
+		p.freeRoom()
+		p.settle()
+		return true
+	}
+
+	if stop, ok := p.running[id]; ok {
+		stop(ErrTaskCancelled)
+		log.Printf("[POOL] running task cancelled: %s", id)
+		return true
+	}
+
+	return false
+}
+
+// Stop refuses new tasks, stops dispatching the queued ones and gives the running tasks until ctx ends to
+// finish before the pool shuts down. The tasks which are still running then are requeued.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	p.draining = true
+	if p.resumed == nil {
+		p.resumed = make(chan struct{})
+	}
+	if p.idle == nil {
+		p.idle = make(chan struct{})
+	}
+	idle := p.idle
+	log.Printf("[POOL] stopping, waiting for %d running tasks", len(p.running))
+	p.settle()
+	p.mu.Unlock()
+
+	var err error
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		err = ctx.Err()
+		log.Printf("[POOL] grace period is over, interrupting the running tasks: %v", err)
+	}
+
+	p.Shutdown()
+	return err
+}
+
+// Shutdown stops the pool at once, the running tasks are interrupted and requeued, the queued and retrying
+// tasks stay pending and their leases are released so any pool can claim them
 func (p *Pool) Shutdown() {
 	log.Println("[POOL] shutdown initiated")
 	p.cancel()
+
+	p.mu.Lock()
+	for id, timer := range p.retrying {
+		timer.Stop()
+		delete(p.retrying, id)
+	}
+	p.mu.Unlock()
+
 	p.wg.Wait()
+
+	if p.deps.TaskService != nil {
+		_ = p.deps.TaskService.ReleaseLeases(context.Background(), p.owner)
+	}
 	log.Println("[POOL] shutdown completed")
 }
 
@@ -72,76 +255,368 @@ func (p *Pool) worker(id int, deps WorkerDeps) {
 	log.Printf("[WORKER-%d] started", id)
 
 	for {
+		ready, resumed := p.dispatch()
+
 		select {
 		case <-p.ctx.Done():
 			log.Printf("[WORKER-%d] stopping", id)
 			return
 
-		case task := <-p.queue:
-			p.processTask(id, *task, deps)
+		case <-p.retire:
+			log.Printf("[WORKER-%d] retired", id)
+			return
+
+		case <-resumed:
+			continue
+
+		case <-ready:
+			if p.take(id, deps) {
+				p.replace(id)
+				return
+			}
 		}
 	}
 }
 
+// take runs the next queued task, it reports whether the worker panicked. The task the worker was
+// running then fails with the panic as far as the worker got with it, the worker is not trusted with
+// another task.
+func (p *Pool) take(id int, deps WorkerDeps) (panicked bool) {
+	task, taskCtx, done := p.next()
+	if task == nil {
+		return false
+	}
+	defer done()
+
+	// The worker moves its own copy along, the queued task may still be read by whoever submitted it
+	current := *task
+	defer func() {
+		if r := recover(); r != nil {
+			err := recovered(r)
+			p.mu.Lock()
+			p.panicked(err)
+			p.mu.Unlock()
+
+			if failed, saveErr := save(deps, fail(current, err), p.workerName(id), err.Error()); saveErr == nil {
+				p.finished(failed)
+			}
+			panicked = true
+		}
+	}()
+
+	if !p.lease(current) {
+		return false
+	}
+
+	p.processTask(taskCtx, id, &current, deps)
+	return false
+}
+
+// next pops the most urgent task of the queue and registers it as running in one step, so a cancel
+// request can never miss a task which is moving from the queue to a worker
+func (p *Pool) next() (*entity.Task, context.Context, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A worker may have taken a signal just before the pool was paused, the signal is handed back
+	if p.resumed != nil {
+		p.signal()
+		return nil, nil, nil
+	}
+
+	task := p.queue.pop()
+	if task == nil {
+		return nil, nil, nil
+	}
+	p.freeRoom()
+
+	taskCtx, stop := context.WithCancelCause(p.ctx)
+	p.running[task.Id] = stop
+
+	return task, taskCtx, func() {
+		p.mu.Lock()
+		delete(p.running, task.Id)
+		p.settle()
+		p.mu.Unlock()
+		stop(nil)
+	}
+}
+
+// processTask runs the task and saves every step of it, task is updated with the attempt as the worker
+// moves it along, so a panic of the worker fails the attempt it started
 func (p *Pool) processTask(
+	ctx context.Context,
 	workerID int,
-	task entity.Task,
+	task *entity.Task,
 	deps WorkerDeps,
 ) {
 	log.Printf("[WORKER-%d] start task %s", workerID, task.Id)
 
-	taskModel, err := start(task)
+	worker := p.workerName(workerID)
+	taskModel, err := start(*task, worker)
+	if err != nil {
+		log.Printf("[WORKER-%d] skip task %s: %v", workerID, task.Id, err)
+		return
+	}
+	defer func() { *task = taskModel }()
+
+	// The task may have been cancelled since it was queued, the stored status decides whether it runs
+	taskModel, err = save(deps, taskModel, worker, fmt.Sprintf("attempt %d started", taskModel.Attempts))
+	if err != nil {
+		return
+	}
+	p.started(taskModel)
+
+	result, err := p.run(ctx, taskModel, deps)
+	if errors.Is(err, ErrTaskTimedOut) {
+		log.Printf("[WORKER-%d] task %s timed out after %s", workerID, task.Id, p.timeout(taskModel))
+		taskModel, _ = save(deps, timeOut(taskModel, err), worker, fmt.Sprintf("attempt %d exceeded %s", taskModel.Attempts, p.timeout(taskModel)))
+	}
+
+	reason := "completed"
 	if err != nil {
+		reason = err.Error()
+	}
+
+	switch {
+	case err == nil:
+		taskModel = complete(taskModel, result)
+	case errors.Is(err, ErrTaskCancelled):
+		taskModel = cancel(taskModel)
+		reason = "cancelled on request"
+		log.Printf("[WORKER-%d] cancelled task %s", workerID, task.Id)
+	case p.ctx.Err() != nil:
+		taskModel = requeue(taskModel)
+		reason = "interrupted by shutdown, requeued"
+		log.Printf("[WORKER-%d] task %s interrupted by shutdown, requeued", workerID, task.Id)
+	case errors.Is(err, handler.ErrUnknownType):
+		taskModel = fail(taskModel, err)
+	case errors.Is(err, ErrTaskPanicked):
+		taskModel = fail(taskModel, err)
+		p.mu.Lock()
+		p.panicked(err.(*panicError))
+		p.mu.Unlock()
+	case taskModel.Retry.CanRetry(taskModel.Attempts):
+		taskModel = retry(taskModel, err, taskModel.Retry.NextDelay(taskModel.Attempts))
+		reason = fmt.Sprintf("attempt %d failed, retrying: %v", taskModel.Attempts, err)
+	case taskModel.Retry.Retryable():
+		taskModel = deadLetter(taskModel, err)
+		reason = fmt.Sprintf("attempts exhausted after %d: %v", taskModel.Attempts, err)
+		log.Printf("[WORKER-%d] task %s moved to dead letter after %d attempts", workerID, task.Id, taskModel.Attempts)
+	case errors.Is(err, ErrTaskTimedOut):
+		// The timed out state is final already
+	default:
+		taskModel = fail(taskModel, err)
+	}
+
+	taskModel, saveErr := save(deps, taskModel, worker, reason)
+	if saveErr == nil {
+		p.finished(taskModel)
+	}
+
+	if saveErr == nil && taskModel.Status == entity.StatusPending && p.ctx.Err() == nil {
+		delay := time.Until(*taskModel.QueuedAt)
+		log.Printf("[WORKER-%d] task %s attempt %d failed, retrying in %s: %v", workerID, task.Id, taskModel.Attempts, delay, err)
+		p.retryAfter(taskModel, delay)
+	}
+
+	p.mu.Lock()
+	p.meter.mark(time.Now())
+	p.mu.Unlock()
+
+	log.Printf("[WORKER-%d] finished task %s", workerID, task.Id)
+}
+
+// workerName identifies a worker across every pool which shares the database
+func (p *Pool) workerName(workerID int) string {
+	return fmt.Sprintf("%s/worker-%d", p.owner, workerID)
+}
+
+// retryAfter puts the task back to the queue once its backoff is over
+func (p *Pool) retryAfter(task entity.Task, delay time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ctx.Err() != nil {
 		return
 	}
-	_, _ = deps.TaskService.Update(context.Background(), entity.Task{
-		UniversalModel: db.UniversalModel{
-			Id:        taskModel.Id,
-			UpdatedAt: time.Now(),
-		},
-		Status:   taskModel.Status,
-		Duration: taskModel.Duration,
+
+	p.retrying[task.Id] = time.AfterFunc(delay, func() {
+		p.mu.Lock()
+		delete(p.retrying, task.Id)
+		p.mu.Unlock()
+
+		if err := p.Submit(&task); err != nil {
+			log.Printf("[POOL] retry of task %s is left to the durable backlog: %v", task.Id, err)
+		}
 	})
+}
+
+func (p *Pool) timeout(task entity.Task) time.Duration {
+	if task.Timeout > 0 {
+		return task.Timeout
+	}
+
+	return p.defaultTimeout
+}
+
+// run executes the task under its deadline, when there is one
+func (p *Pool) run(ctx context.Context, task entity.Task, deps WorkerDeps) (json.RawMessage, error) {
+	if timeout := p.timeout(task); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrTaskTimedOut)
+		defer cancel()
+	}
+
+	return execute(ctx, task, deps)
+}
+
+type outcome struct {
+	result json.RawMessage
+	err    error
+}
+
+// execute runs the handler of the task type. The worker stops waiting as soon as the task context is done
+// and reports the cause, so a handler which ignores its context can not hold the worker past the deadline.
+func execute(ctx context.Context, task entity.Task, deps WorkerDeps) (json.RawMessage, error) {
+	h, err := deps.Handlers.Get(task.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		// A panicking handler must not take the process down, it fails its task
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: recovered(r)}
+			}
+		}()
+
+		result, err := h.Handle(ctx, task.Payload)
+		done <- outcome{result: result, err: err}
+	}()
 
 	select {
-	case <-p.ctx.Done():
-		fail(task)
-	case <-time.After(task.Duration):
-		complete(task)
-	}
-
-	_, _ = deps.TaskService.Update(context.Background(), entity.Task{
-		UniversalModel: db.UniversalModel{
-			Id:        taskModel.Id,
-			UpdatedAt: time.Now(),
-		},
-		Status:   taskModel.Status,
-		Duration: taskModel.Duration,
-	})
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	case out := <-done:
+		if out.err != nil && ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
 
-	log.Printf("[WORKER-%d] finished task %s", workerID, task.Id)
+		if out.err != nil {
+			return nil, out.err
+		}
+
+		return out.result, checkResult(out.result)
+	}
 }
 
-var ErrInvalidStateTransition = errors.New("invalid task state transition")
+// checkResult makes sure the result can be stored with the task
+func checkResult(result json.RawMessage) error {
+	if len(result) == 0 {
+		return nil
+	}
+
+	if len(result) > entity.MaxResultSize {
+		return ErrResultTooBig
+	}
 
-func start(task entity.Task) (entity.Task, error) {
-	if task.Status != entity.StatusPending {
-		return entity.Task{}, ErrInvalidStateTransition
+	if !json.Valid(result) {
+		return ErrInvalidResult
 	}
 
-	task.Status = entity.StatusRunning
+	return nil
+}
+
+// save stores the task through the state machine of the service, a rejected transition leaves the stored task as is
+func save(deps WorkerDeps, task entity.Task, worker string, reason string) (entity.Task, error) {
 	task.UpdatedAt = time.Now()
+	_, err := deps.TaskService.Transition(context.Background(), task, entity.Transition{
+		WorkerId: worker,
+		Reason:   reason,
+	})
+	if err != nil {
+		log.Printf("[POOL] cannot save task %s as %s: %v", task.Id, task.Status, err)
+		return task, err
+	}
+
 	return task, nil
 }
 
-func complete(task entity.Task) entity.Task {
-	task.Status = entity.StatusCompleted
+func start(task entity.Task, worker string) (entity.Task, error) {
+	if err := task.TransitionTo(entity.StatusRunning); err != nil {
+		return entity.Task{}, err
+	}
+
+	now := time.Now()
+	task.StartedAt = &now
+	task.FinishedAt = nil
+	task.WorkerId = worker
+	task.Attempts++
 	task.UpdatedAt = time.Now()
-	return task
+	return task, nil
 }
 
-func fail(task entity.Task) entity.Task {
+func complete(task entity.Task, result json.RawMessage) entity.Task {
+	task.Status = entity.StatusCompleted
+	task.Result = result
+	task.Error = ""
+	task.LastError = ""
+	return finish(task)
+}
+
+func fail(task entity.Task, err error) entity.Task {
 	task.Status = entity.StatusFailed
-	task.UpdatedAt = time.Now()
+	task.Error = err.Error()
+	task.LastError = err.Error()
+	return finish(task)
+}
+
+// retry sends the task back to the queue, it is queued again once its backoff is over
+func retry(task entity.Task, err error, delay time.Duration) entity.Task {
+	task = finish(task)
+	task.Status = entity.StatusPending
+	task.LastError = err.Error()
+	queuedAt := task.UpdatedAt.Add(delay)
+	task.QueuedAt = &queuedAt
+	return task
+}
+
+// requeue hands a task interrupted by the shutdown back to the durable backlog, the interrupted attempt
+// does not count against its retry budget
+func requeue(task entity.Task) entity.Task {
+	task = finish(task)
+	task.Status = entity.StatusPending
+	task.Attempts = max(task.Attempts-1, 0)
+	task.QueuedAt = &task.UpdatedAt
 	return task
 }
+
+func deadLetter(task entity.Task, err error) entity.Task {
+	task.Status = entity.StatusDeadLetter
+	task.Error = err.Error()
+	task.LastError = err.Error()
+	return finish(task)
+}
+
+// finish closes the current attempt
+func finish(task entity.Task) entity.Task {
+	now := time.Now()
+	task.FinishedAt = &now
+	task.UpdatedAt = now
+	return task
+}
+
+func timeOut(task entity.Task, err error) entity.Task {
+	task.Status = entity.StatusTimedOut
+	task.Error = err.Error()
+	task.LastError = err.Error()
+	return finish(task)
+}
+
+func cancel(task entity.Task) entity.Task {
+	task.Status = entity.StatusCancelled
+	return finish(task)
+}
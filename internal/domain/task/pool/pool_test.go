@@ -0,0 +1,870 @@
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+)
+
+type fakeTaskService struct {
+	task.TaskService
+
+	mu          sync.Mutex
+	updates     []entity.Task
+	transitions []entity.Transition
+	backlog     []entity.Task
+	refusals    map[uuid.UUID]bool
+	// rejected tasks fail every transition, like the tasks which were cancelled behind the pool's back
+	rejected map[uuid.UUID]bool
+	// crashing tasks panic when they are saved as running, like a bug in the worker itself
+	crashing map[uuid.UUID]bool
+	// cancelled tasks are stored as cancelled, like the tasks cancelled through another executor
+	cancelled map[uuid.UUID]bool
+	released  bool
+	// claims holds every claim back until it is closed, like a database which stopped answering
+	claims chan struct{}
+}
+
+func (f *fakeTaskService) ClaimPending(ctx context.Context, owner string, limit int, ttl time.Duration) ([]entity.Task, error) {
+	if f.claims != nil {
+		select {
+		case <-f.claims:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := min(limit, len(f.backlog))
+	claimed := f.backlog[:n]
+	f.backlog = f.backlog[n:]
+	return claimed, nil
+}
+
+func (f *fakeTaskService) Lease(ctx context.Context, id uuid.UUID, owner string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.refusals[id], nil
+}
+
+func (f *fakeTaskService) RenewLeases(ctx context.Context, owner string, ids []uuid.UUID, ttl time.Duration) error {
+	return nil
+}
+
+func (f *fakeTaskService) List(ctx context.Context, filter entity.TaskFilter, portion request.Portion) ([]entity.Task, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := make([]entity.Task, 0)
+	for _, id := range filter.Ids {
+		if parsed := uuid.MustParse(id); f.cancelled[parsed] {
+			res = append(res, entity.Task{UniversalModel: db.UniversalModel{Id: parsed}, Status: entity.StatusCancelled})
+		}
+	}
+	return res, int64(len(res)), nil
+}
+
+func (f *fakeTaskService) ReleaseLeases(ctx context.Context, owner string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.released = true
+	return nil
+}
+
+func (f *fakeTaskService) ReclaimExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeTaskService) Transition(ctx context.Context, in entity.Task, transition entity.Transition) (entity.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.rejected[in.Id] {
+		return entity.Task{}, entity.ErrInvalidStateTransition
+	}
+	if f.crashing[in.Id] && in.Status == entity.StatusRunning {
+		panic("worker crashed")
+	}
+	f.updates = append(f.updates, in)
+	f.transitions = append(f.transitions, transition)
+	return in, nil
+}
+
+func (f *fakeTaskService) statusOf(id uuid.UUID) entity.Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status := entity.Status("")
+	for _, update := range f.updates {
+		if update.Id == id {
+			status = update.Status
+		}
+	}
+	return status
+}
+
+func (f *fakeTaskService) lastUpdate(id uuid.UUID) entity.Task {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	last := entity.Task{}
+	for _, update := range f.updates {
+		if update.Id == id {
+			last = update
+		}
+	}
+	return last
+}
+
+func (f *fakeTaskService) lastStatus() entity.Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.updates) == 0 {
+		return ""
+	}
+	return f.updates[len(f.updates)-1].Status
+}
+
+func newTask(duration time.Duration) *entity.Task {
+	return &entity.Task{
+		UniversalModel: db.UniversalModel{Id: uuid.New()},
+		Title:          "test",
+		Description:    "test",
+		Status:         entity.StatusPending,
+		Type:           handler.TypeSleep,
+		Payload:        handler.NewSleepPayload(duration),
+		Duration:       duration,
+	}
+}
+
+func newDeps(svc *fakeTaskService) WorkerDeps {
+	handlers := handler.NewRegistry()
+	handlers.MustRegister(handler.TypeSleep, handler.Sleep())
+	handlers.MustRegister("fail", handler.HandlerFunc(func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+		return nil, errors.New("boom")
+	}))
+	handlers.MustRegister("panic", handler.HandlerFunc(func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+		panic("kaboom")
+	}))
+	handlers.MustRegister("echo", handler.HandlerFunc(func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+		return payload, nil
+	}))
+
+	return WorkerDeps{TaskService: svc, Handlers: handlers}
+}
+
+func TestCancel_QueuedTask(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+
+	item := newTask(time.Second)
+	assert.NoError(t, p.Submit(item))
+
+	assert.True(t, p.Cancel(item.Id))
+	assert.Zero(t, p.queue.Len())
+	assert.False(t, p.Cancel(item.Id))
+}
+
+func TestCancel_RunningTask(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(time.Minute)
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.lastStatus() == entity.StatusRunning
+	}, time.Second, 10*time.Millisecond)
+
+	assert.True(t, p.Cancel(item.Id))
+	assert.Eventually(t, func() bool {
+		return svc.lastStatus() == entity.StatusCancelled
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRenew_CancelsTaskCancelledElsewhere(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	p.leaseTTL = 30 * time.Millisecond
+	svc := &fakeTaskService{cancelled: map[uuid.UUID]bool{}}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(time.Minute)
+	assert.NoError(t, p.Submit(item))
+	assert.Eventually(t, func() bool {
+		return svc.lastStatus() == entity.StatusRunning
+	}, time.Second, 10*time.Millisecond)
+
+	svc.mu.Lock()
+	svc.cancelled[item.Id] = true
+	svc.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return svc.lastStatus() == entity.StatusCancelled
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSubmit_PoolFull(t *testing.T) {
+	p := New(context.Background(), 1, 1)
+
+	assert.NoError(t, p.Submit(newTask(time.Second)))
+	assert.ErrorIs(t, p.Submit(newTask(time.Second)), ErrPoolFull)
+}
+
+func TestStart_ClaimsDurableBacklog(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	item := newTask(time.Millisecond)
+	svc := &fakeTaskService{backlog: []entity.Task{*item}}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusCompleted
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWorker_SkipsTaskLeasedByAnotherPool(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	taken := newTask(time.Millisecond)
+	free := newTask(time.Millisecond)
+	svc := &fakeTaskService{refusals: map[uuid.UUID]bool{taken.Id: true}}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	assert.NoError(t, p.Submit(taken))
+	assert.NoError(t, p.Submit(free))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(free.Id) == entity.StatusCompleted
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, entity.Status(""), svc.statusOf(taken.Id))
+}
+
+func TestWorker_RecordsHandlerError(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(0)
+	item.Type = "fail"
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusFailed
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "boom", svc.lastUpdate(item.Id).LastError)
+}
+
+func TestWorker_FailsUnknownType(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(0)
+	item.Type = "missing"
+	item.Retry = entity.RetryPolicy{MaxAttempts: 3}
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusFailed
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, svc.lastUpdate(item.Id).LastError, handler.ErrUnknownType.Error())
+}
+
+func TestWorker_RecordsResult(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(0)
+	item.Type = "echo"
+	item.Payload = json.RawMessage(`{"answer":42}`)
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusCompleted
+	}, time.Second, 10*time.Millisecond)
+	assert.JSONEq(t, `{"answer":42}`, string(svc.lastUpdate(item.Id).Result))
+}
+
+func TestWorker_FailsOversizedResult(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(0)
+	item.Type = "echo"
+	item.Payload = json.RawMessage(`"` + strings.Repeat("x", entity.MaxResultSize) + `"`)
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusFailed
+	}, time.Second, 10*time.Millisecond)
+	last := svc.lastUpdate(item.Id)
+	assert.Equal(t, ErrResultTooBig.Error(), last.Error)
+	assert.Empty(t, last.Result)
+}
+
+func TestWorker_TimesOutTask(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(time.Second)
+	item.Timeout = 50 * time.Millisecond
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusTimedOut
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, ErrTaskTimedOut.Error(), svc.lastUpdate(item.Id).Error)
+}
+
+func TestWorker_DefaultTimeoutStopsHandlerIgnoringContext(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	p.SetDefaultTimeout(50 * time.Millisecond)
+	svc := &fakeTaskService{}
+	deps := newDeps(svc)
+	deps.Handlers.MustRegister("stuck", handler.HandlerFunc(func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}))
+	p.Start(deps)
+	defer p.Shutdown()
+
+	item := newTask(0)
+	item.Type = "stuck"
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusTimedOut
+	}, 500*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestWorker_RetriesTimedOutTask(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(time.Second)
+	item.Timeout = 20 * time.Millisecond
+	item.Retry = entity.RetryPolicy{MaxAttempts: 2, Backoff: entity.BackoffFixed}
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusDeadLetter
+	}, time.Second, 10*time.Millisecond)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	var statuses []entity.Status
+	for _, update := range svc.updates {
+		statuses = append(statuses, update.Status)
+	}
+	assert.Equal(t, []entity.Status{
+		entity.StatusRunning, entity.StatusTimedOut, entity.StatusPending,
+		entity.StatusRunning, entity.StatusTimedOut, entity.StatusDeadLetter,
+	}, statuses)
+}
+
+func TestWorker_RetriesFailingTaskUntilDeadLetter(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	delay := 50 * time.Millisecond
+	item := newTask(0)
+	item.Type = "fail"
+	item.Retry = entity.RetryPolicy{MaxAttempts: 3, Backoff: entity.BackoffFixed, Delay: delay}
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusDeadLetter
+	}, 2*time.Second, 10*time.Millisecond)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	var statuses []entity.Status
+	var attempts []int
+	for _, update := range svc.updates {
+		statuses = append(statuses, update.Status)
+		attempts = append(attempts, update.Attempts)
+	}
+	assert.Equal(t, []entity.Status{
+		entity.StatusRunning, entity.StatusPending,
+		entity.StatusRunning, entity.StatusPending,
+		entity.StatusRunning, entity.StatusDeadLetter,
+	}, statuses)
+	assert.Equal(t, []int{1, 1, 2, 2, 3, 3}, attempts)
+
+	// Every retry is queued once its backoff is over and is not started before
+	for _, i := range []int{1, 3} {
+		retried, next := svc.updates[i], svc.updates[i+1]
+		assert.Equal(t, delay, retried.QueuedAt.Sub(*retried.FinishedAt))
+		assert.False(t, next.StartedAt.Before(*retried.QueuedAt))
+	}
+	assert.Equal(t, "boom", svc.updates[5].LastError)
+}
+
+func TestWorker_SkipsTaskWhoseStartIsRejected(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	item := newTask(0)
+	item.Type = "counted"
+	svc := &fakeTaskService{rejected: map[uuid.UUID]bool{item.Id: true}}
+	deps := newDeps(svc)
+	var calls atomic.Int32
+	deps.Handlers.MustRegister("counted", handler.HandlerFunc(func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+		calls.Add(1)
+		return nil, nil
+	}))
+	p.Start(deps)
+	defer p.Shutdown()
+
+	assert.NoError(t, p.Submit(item))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(0), calls.Load())
+	assert.Empty(t, svc.statusOf(item.Id))
+}
+
+func TestWorker_RecordsTransitionReasons(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(0)
+	item.Type = "fail"
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusFailed
+	}, time.Second, 10*time.Millisecond)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	assert.Len(t, svc.transitions, 2)
+	assert.Equal(t, "attempt 1 started", svc.transitions[0].Reason)
+	assert.Equal(t, "boom", svc.transitions[1].Reason)
+	assert.Equal(t, p.workerName(1), svc.transitions[1].WorkerId)
+}
+
+func TestWorker_RecordsTiming(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(20 * time.Millisecond)
+	queuedAt := time.Now()
+	item.QueuedAt = &queuedAt
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusCompleted
+	}, time.Second, 10*time.Millisecond)
+	last := svc.lastUpdate(item.Id)
+	assert.Equal(t, p.workerName(1), last.WorkerId)
+	assert.NotNil(t, last.StartedAt)
+	assert.NotNil(t, last.FinishedAt)
+	assert.GreaterOrEqual(t, last.RunTime(time.Now()), 20*time.Millisecond)
+}
+
+func TestScale_GrowsWithQueueDepth(t *testing.T) {
+	p := New(context.Background(), 1, 10)
+	assert.NoError(t, p.SetScaling(1, 4, time.Minute))
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, p.Submit(newTask(200*time.Millisecond)))
+	}
+	p.scale(time.Now())
+
+	assert.Equal(t, 4, p.Stats().Workers)
+	assert.Eventually(t, func() bool {
+		return p.Stats().BusyWorkers == 4
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestScale_ShrinksToMinimumWhenIdle(t *testing.T) {
+	p := New(context.Background(), 3, 10)
+	assert.NoError(t, p.SetScaling(1, 4, time.Second))
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	now := time.Now()
+	p.scale(now)
+	assert.Equal(t, 3, p.Stats().Workers)
+	p.scale(now.Add(time.Second))
+	assert.Equal(t, 2, p.Stats().Workers)
+	p.scale(now.Add(2 * time.Second))
+	assert.Equal(t, 1, p.Stats().Workers)
+	p.scale(now.Add(3 * time.Second))
+	p.scale(now.Add(4 * time.Second))
+	assert.Equal(t, 1, p.Stats().Workers)
+}
+
+func TestSetScaling_RejectsBoundsLeavingOutWorkers(t *testing.T) {
+	p := New(context.Background(), 3, 10)
+
+	assert.ErrorIs(t, p.SetScaling(4, 0, time.Minute), ErrInvalidWorkerCount)
+	assert.ErrorIs(t, p.SetScaling(0, 2, time.Minute), ErrInvalidWorkerCount)
+	assert.ErrorIs(t, p.SetScaling(-1, 0, time.Minute), ErrInvalidWorkerCount)
+	assert.NoError(t, p.SetScaling(3, 3, time.Minute))
+}
+
+func TestSetWorkers_OutOfBounds(t *testing.T) {
+	p := New(context.Background(), 2, 10)
+	assert.NoError(t, p.SetScaling(1, 4, time.Minute))
+
+	assert.ErrorIs(t, p.SetWorkers(0), ErrInvalidWorkerCount)
+	assert.ErrorIs(t, p.SetWorkers(5), ErrInvalidWorkerCount)
+}
+
+func TestSetWorkers_RetiredWorkerFinishesItsTask(t *testing.T) {
+	p := New(context.Background(), 2, 10)
+	assert.NoError(t, p.SetScaling(1, 2, time.Minute))
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	first := newTask(150 * time.Millisecond)
+	second := newTask(150 * time.Millisecond)
+	assert.NoError(t, p.Submit(first))
+	assert.NoError(t, p.Submit(second))
+	assert.Eventually(t, func() bool {
+		return p.Stats().BusyWorkers == 2
+	}, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, p.SetWorkers(1))
+	assert.Equal(t, 1, p.Stats().Workers)
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(first.Id) == entity.StatusCompleted && svc.statusOf(second.Id) == entity.StatusCompleted
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPause_HoldsDispatchUntilResume(t *testing.T) {
+	p := New(context.Background(), 2, 10)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	p.Pause()
+	item := newTask(time.Millisecond)
+	assert.NoError(t, p.Submit(item))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, entity.Status(""), svc.statusOf(item.Id))
+	assert.True(t, p.Stats().Paused)
+	assert.Equal(t, 1, p.Stats().QueueLength)
+
+	p.Resume()
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusCompleted
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, p.Stats().Paused)
+}
+
+func TestDrain_RefusesTasksAndWaitsForQueue(t *testing.T) {
+	p := New(context.Background(), 1, 10)
+	svc := &fakeTaskService{}
+	p.Pause()
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	first, second := newTask(50*time.Millisecond), newTask(50*time.Millisecond)
+	assert.NoError(t, p.Submit(first))
+	assert.NoError(t, p.Submit(second))
+
+	assert.NoError(t, p.Drain(context.Background()))
+	assert.Equal(t, entity.StatusCompleted, svc.statusOf(first.Id))
+	assert.Equal(t, entity.StatusCompleted, svc.statusOf(second.Id))
+	assert.ErrorIs(t, p.Submit(newTask(time.Millisecond)), ErrPoolDraining)
+	assert.True(t, p.Stats().Draining)
+
+	p.Resume()
+	assert.NoError(t, p.Submit(newTask(time.Millisecond)))
+}
+
+func TestDrain_StopsAtContextDeadline(t *testing.T) {
+	p := New(context.Background(), 1, 10)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	assert.NoError(t, p.Submit(newTask(time.Second)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Drain(ctx), context.DeadlineExceeded)
+}
+
+func TestStats_Throughput(t *testing.T) {
+	m := meter{}
+	now := time.Now()
+
+	for i := 0; i < 30; i++ {
+		m.mark(now.Add(-time.Duration(i) * time.Second))
+	}
+	m.mark(now.Add(-90 * time.Second))
+
+	assert.EqualValues(t, 31, m.total)
+	assert.InDelta(t, 0.5, m.rate(now), 0.001)
+}
+
+func TestAdmit_RejectsWhenFull(t *testing.T) {
+	p := New(context.Background(), 1, 1)
+	p.SetSubmitPolicy(PolicyReject, 0)
+
+	assert.NoError(t, p.Admit(context.Background()))
+	assert.NoError(t, p.Submit(newTask(time.Second)))
+	assert.ErrorIs(t, p.Admit(context.Background()), ErrPoolFull)
+}
+
+func TestAdmit_BacklogAcceptsWhenFull(t *testing.T) {
+	p := New(context.Background(), 1, 1)
+
+	assert.NoError(t, p.Submit(newTask(time.Second)))
+	assert.NoError(t, p.Admit(context.Background()))
+}
+
+func TestAdmit_BlockWaitsForRoom(t *testing.T) {
+	p := New(context.Background(), 1, 1)
+	p.SetSubmitPolicy(PolicyBlock, time.Second)
+	item := newTask(time.Second)
+	assert.NoError(t, p.Submit(item))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		p.Cancel(item.Id)
+	}()
+
+	assert.NoError(t, p.Admit(context.Background()))
+}
+
+func TestAdmit_BlockTimesOut(t *testing.T) {
+	p := New(context.Background(), 1, 1)
+	p.SetSubmitPolicy(PolicyBlock, 50*time.Millisecond)
+	assert.NoError(t, p.Submit(newTask(time.Second)))
+
+	started := time.Now()
+	assert.ErrorIs(t, p.Admit(context.Background()), ErrPoolFull)
+	assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+}
+
+func TestAdmit_RefusesWhileDraining(t *testing.T) {
+	p := New(context.Background(), 1, 1)
+
+	assert.NoError(t, p.Drain(context.Background()))
+	assert.ErrorIs(t, p.Admit(context.Background()), ErrPoolDraining)
+}
+
+func TestParseSubmitPolicy(t *testing.T) {
+	policy, err := ParseSubmitPolicy("block")
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyBlock, policy)
+
+	_, err = ParseSubmitPolicy("drop")
+	assert.ErrorIs(t, err, ErrInvalidSubmitPolicy)
+}
+
+func TestRetryAfter_FollowsThroughput(t *testing.T) {
+	p := New(context.Background(), 1, 1)
+	assert.Equal(t, maxRetryAfter, p.RetryAfter())
+
+	now := time.Now()
+	for i := 0; i < 30; i++ {
+		p.meter.mark(now)
+	}
+	assert.Equal(t, 2*time.Second, p.RetryAfter())
+}
+
+func TestStop_WaitsForRunningTasks(t *testing.T) {
+	p := New(context.Background(), 1, 10)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+
+	running, queued := newTask(100*time.Millisecond), newTask(time.Millisecond)
+	assert.NoError(t, p.Submit(running))
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(running.Id) == entity.StatusRunning
+	}, time.Second, 5*time.Millisecond)
+	assert.NoError(t, p.Submit(queued))
+
+	assert.NoError(t, p.Stop(context.Background()))
+	assert.Equal(t, entity.StatusCompleted, svc.statusOf(running.Id))
+	assert.Equal(t, entity.Status(""), svc.statusOf(queued.Id))
+	assert.ErrorIs(t, p.Submit(newTask(time.Millisecond)), ErrPoolDraining)
+	assert.True(t, svc.released)
+}
+
+func TestStop_RequeuesTasksRunningAfterGrace(t *testing.T) {
+	p := New(context.Background(), 1, 10)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+
+	item := newTask(time.Minute)
+	assert.NoError(t, p.Submit(item))
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusRunning
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Stop(ctx), context.DeadlineExceeded)
+
+	last := svc.lastUpdate(item.Id)
+	assert.Equal(t, entity.StatusPending, last.Status)
+	assert.Zero(t, last.Attempts)
+	assert.Empty(t, last.Error)
+	assert.Zero(t, p.Stats().Retrying)
+}
+
+func TestWorker_RecoversHandlerPanic(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	item := newTask(0)
+	item.Type = "panic"
+	item.Retry = entity.RetryPolicy{MaxAttempts: 3}
+	assert.NoError(t, p.Submit(item))
+
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusFailed
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, svc.lastUpdate(item.Id).Error, "task panicked: kaboom")
+	assert.Contains(t, svc.lastUpdate(item.Id).Error, "pool_test.go")
+	assert.EqualValues(t, 1, p.Stats().Panics)
+
+	next := newTask(time.Millisecond)
+	assert.NoError(t, p.Submit(next))
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(next.Id) == entity.StatusCompleted
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWorker_ReplacedAfterPanic(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	item := newTask(time.Millisecond)
+	svc := &fakeTaskService{crashing: map[uuid.UUID]bool{item.Id: true}}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	assert.NoError(t, p.Submit(item))
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusFailed
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, svc.lastUpdate(item.Id).Error, "task panicked: worker crashed")
+
+	next := newTask(time.Millisecond)
+	assert.NoError(t, p.Submit(next))
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(next.Id) == entity.StatusCompleted
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, p.workerName(2), svc.lastUpdate(next.Id).WorkerId)
+
+	stats := p.Stats()
+	assert.EqualValues(t, 1, stats.Panics)
+	assert.Equal(t, 1, stats.Workers)
+	assert.Zero(t, stats.BusyWorkers)
+}
+
+// panickingObserver panics when it is told a task started, like a bug in the worker after the attempt is stored
+type panickingObserver struct{}
+
+func (panickingObserver) TaskStarted(task entity.Task)  { panic("observer crashed") }
+func (panickingObserver) TaskFinished(task entity.Task) {}
+
+func TestWorker_PanicFailsTheStartedAttempt(t *testing.T) {
+	p := New(context.Background(), 1, 2)
+	svc := &fakeTaskService{}
+	deps := newDeps(svc)
+	deps.Observers = []Observer{panickingObserver{}}
+	p.Start(deps)
+	defer p.Shutdown()
+
+	item := newTask(time.Millisecond)
+	assert.NoError(t, p.Submit(item))
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(item.Id) == entity.StatusFailed
+	}, time.Second, 10*time.Millisecond)
+
+	failed := svc.lastUpdate(item.Id)
+	assert.Contains(t, failed.Error, "task panicked: observer crashed")
+	assert.Equal(t, 1, failed.Attempts)
+	assert.NotNil(t, failed.StartedAt)
+	assert.NotNil(t, failed.FinishedAt)
+	assert.Equal(t, p.workerName(1), failed.WorkerId)
+}
+
+func TestReady_FollowsPoolState(t *testing.T) {
+	p := New(context.Background(), 1, 1)
+	assert.ErrorIs(t, p.Ready(context.Background()), ErrPoolNotStarted)
+	assert.NoError(t, p.Live(context.Background()))
+
+	svc := &fakeTaskService{}
+	p.Start(newDeps(svc))
+	assert.NoError(t, p.Ready(context.Background()))
+
+	p.Pause()
+	assert.ErrorIs(t, p.Ready(context.Background()), ErrPoolPaused)
+	p.Resume()
+
+	running, queued := newTask(200*time.Millisecond), newTask(time.Millisecond)
+	assert.NoError(t, p.Submit(running))
+	assert.Eventually(t, func() bool {
+		return svc.statusOf(running.Id) == entity.StatusRunning
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, p.Submit(queued))
+	assert.ErrorIs(t, p.Ready(context.Background()), ErrPoolSaturated)
+
+	assert.NoError(t, p.Drain(context.Background()))
+	assert.ErrorIs(t, p.Ready(context.Background()), ErrPoolDraining)
+
+	p.Shutdown()
+	assert.ErrorIs(t, p.Ready(context.Background()), ErrPoolStopped)
+	assert.ErrorIs(t, p.Live(context.Background()), ErrPoolStopped)
+}
+
+func TestLive_FailsWhileFeedIsStuck(t *testing.T) {
+	p := New(context.Background(), 1, 1)
+	p.stallTimeout = 50 * time.Millisecond
+	p.pollInterval = 10 * time.Millisecond
+	svc := &fakeTaskService{claims: make(chan struct{})}
+	p.Start(newDeps(svc))
+	defer p.Shutdown()
+
+	assert.NoError(t, p.Live(context.Background()))
+	assert.Eventually(t, func() bool {
+		return errors.Is(p.Live(context.Background()), ErrPoolStalled)
+	}, time.Second, 10*time.Millisecond)
+
+	close(svc.claims)
+	assert.Eventually(t, func() bool {
+		return p.Live(context.Background()) == nil
+	}, time.Second, 10*time.Millisecond)
+}
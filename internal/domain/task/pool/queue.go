@@ -0,0 +1,109 @@
+package pool
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+)
+
+type queueItem struct {
+	task     *entity.Task
+	queuedAt time.Time
+	seq      uint64
+	index    int
+}
+
+// taskQueue is a priority heap of the tasks waiting for a worker. Every entity.PriorityAging of
+// waiting since the task was last queued is worth one priority level, so a retried, requeued or
+// re-driven task does not keep the age of its earlier attempts. The ordering between two items never
+// changes over time so the heap stays valid while the items age.
+type taskQueue struct {
+	items []*queueItem
+	seq   uint64
+}
+
+func newTaskQueue(capacity int) *taskQueue {
+	return &taskQueue{items: make([]*queueItem, 0, capacity)}
+}
+
+func (q *taskQueue) Len() int { return len(q.items) }
+
+func (q *taskQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	score := time.Duration(a.task.Priority-b.task.Priority)*entity.PriorityAging + b.queuedAt.Sub(a.queuedAt)
+	if score != 0 {
+		return score > 0
+	}
+
+	return a.seq < b.seq
+}
+
+func (q *taskQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.items[i].index = i
+	q.items[j].index = j
+}
+
+func (q *taskQueue) Push(x any) {
+	item := x.(*queueItem)
+	item.index = len(q.items)
+	q.items = append(q.items, item)
+}
+
+func (q *taskQueue) Pop() any {
+	n := len(q.items)
+	item := q.items[n-1]
+	q.items[n-1] = nil
+	q.items = q.items[:n-1]
+	item.index = -1
+	return item
+}
+
+func (q *taskQueue) push(task *entity.Task) {
+	queuedAt := time.Now()
+	if task.QueuedAt != nil {
+		queuedAt = *task.QueuedAt
+	}
+
+	q.seq++
+	heap.Push(q, &queueItem{task: task, queuedAt: queuedAt, seq: q.seq})
+}
+
+func (q *taskQueue) pop() *entity.Task {
+	if q.Len() == 0 {
+		return nil
+	}
+
+	return heap.Pop(q).(*queueItem).task
+}
+
+func (q *taskQueue) find(id uuid.UUID) *queueItem {
+	for _, item := range q.items {
+		if item.task.Id == id {
+			return item
+		}
+	}
+
+	return nil
+}
+
+func (q *taskQueue) remove(id uuid.UUID) bool {
+	item := q.find(id)
+	if item == nil {
+		return false
+	}
+
+	heap.Remove(q, item.index)
+	return true
+}
+
+func (q *taskQueue) ids() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(q.items))
+	for _, item := range q.items {
+		ids = append(ids, item.task.Id)
+	}
+
+	return ids
+}
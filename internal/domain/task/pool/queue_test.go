@@ -0,0 +1,85 @@
+package pool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+)
+
+func queuedTask(priority int, queuedAt time.Time) *entity.Task {
+	return &entity.Task{
+		UniversalModel: db.UniversalModel{Id: uuid.New(), CreatedAt: queuedAt},
+		Priority:       priority,
+		QueuedAt:       &queuedAt,
+	}
+}
+
+func TestTaskQueue_HighestPriorityFirst(t *testing.T) {
+	now := time.Now()
+	q := newTaskQueue(3)
+	low := queuedTask(1, now)
+	high := queuedTask(9, now)
+	normal := queuedTask(entity.DefaultPriority, now)
+	q.push(low)
+	q.push(high)
+	q.push(normal)
+
+	assert.Equal(t, high, q.pop())
+	assert.Equal(t, normal, q.pop())
+	assert.Equal(t, low, q.pop())
+	assert.Nil(t, q.pop())
+}
+
+func TestTaskQueue_SamePriorityIsFifo(t *testing.T) {
+	now := time.Now()
+	q := newTaskQueue(2)
+	first := queuedTask(entity.DefaultPriority, now)
+	second := queuedTask(entity.DefaultPriority, now)
+	q.push(first)
+	q.push(second)
+
+	assert.Equal(t, first, q.pop())
+	assert.Equal(t, second, q.pop())
+}
+
+func TestTaskQueue_AgingPreventsStarvation(t *testing.T) {
+	now := time.Now()
+	q := newTaskQueue(2)
+	old := queuedTask(1, now.Add(-5*entity.PriorityAging))
+	fresh := queuedTask(5, now)
+	q.push(fresh)
+	q.push(old)
+
+	assert.Equal(t, old, q.pop())
+	assert.Equal(t, fresh, q.pop())
+}
+
+func TestTaskQueue_RequeuedTaskDoesNotKeepItsAge(t *testing.T) {
+	now := time.Now()
+	q := newTaskQueue(2)
+	// The task was created long ago and is queued again for a retry
+	retried := queuedTask(1, now)
+	retried.CreatedAt = now.Add(-5 * entity.PriorityAging)
+	fresh := queuedTask(5, now)
+	q.push(retried)
+	q.push(fresh)
+
+	assert.Equal(t, fresh, q.pop())
+	assert.Equal(t, retried, q.pop())
+}
+
+func TestTaskQueue_Remove(t *testing.T) {
+	q := newTaskQueue(2)
+	a := queuedTask(1, time.Now())
+	b := queuedTask(2, time.Now())
+	q.push(a)
+	q.push(b)
+
+	assert.True(t, q.remove(b.Id))
+	assert.False(t, q.remove(b.Id))
+	assert.Equal(t, []uuid.UUID{a.Id}, q.ids())
+}
@@ -0,0 +1,136 @@
+package pool
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	defaultIdleTimeout   = 30 * time.Second
+	defaultScaleInterval = time.Second
+)
+
+var ErrInvalidWorkerCount = errors.New("worker count is out of the pool bounds")
+
+// SetScaling lets the pool grow up to maxWorkers while tasks wait in the queue and shrink down to
+// minWorkers while it is idle, one worker for every idle period. Zero bounds keep the initial worker
+// count, bounds which leave it out fail with ErrInvalidWorkerCount. It must be called before Start.
+func (p *Pool) SetScaling(minWorkers int, maxWorkers int, idle time.Duration) error {
+	if minWorkers < 0 || minWorkers > p.target || (maxWorkers != 0 && maxWorkers < p.target) {
+		return ErrInvalidWorkerCount
+	}
+
+	if minWorkers > 0 {
+		p.minWorkers = minWorkers
+	}
+
+	if maxWorkers > 0 {
+		p.maxWorkers = maxWorkers
+	}
+
+	if idle > 0 {
+		p.idleTimeout = idle
+	}
+
+	return nil
+}
+
+// SetWorkers resizes the pool to n workers right away, it keeps growing with the load and shrinking to
+// its minimum while idle afterwards. The retired workers finish their current task before they stop.
+func (p *Pool) SetWorkers(n int) error {
+	if n < p.minWorkers || n > p.maxWorkers {
+		return ErrInvalidWorkerCount
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	log.Printf("[POOL] worker target changed from %d to %d", p.target, n)
+	p.target = n
+	p.idleSince = time.Time{}
+	p.resize(n)
+	return nil
+}
+
+// autoscale adjusts the worker count to the load until the pool stops
+func (p *Pool) autoscale() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.scaleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.scale(now)
+		}
+	}
+}
+
+// scale grows the pool when the queued tasks outnumber the idle workers, and retires one worker above
+// the minimum once the pool has been idle for the idle timeout
+func (p *Pool) scale(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A paused pool does not dispatch, so the waiting tasks say nothing about the load
+	if p.resumed != nil {
+		p.idleSince = time.Time{}
+		return
+	}
+
+	idle := p.live - len(p.running)
+	depth := p.queue.Len()
+
+	if depth > idle && p.live < p.maxWorkers {
+		n := min(p.live+depth-max(idle, 0), p.maxWorkers)
+		log.Printf("[POOL] %d tasks are waiting, growing from %d to %d workers", depth, p.live, n)
+		p.resize(n)
+		p.idleSince = time.Time{}
+		return
+	}
+
+	if depth > 0 || idle <= 0 || p.live <= p.minWorkers {
+		p.idleSince = time.Time{}
+		return
+	}
+
+	if p.idleSince.IsZero() {
+		p.idleSince = now
+		return
+	}
+
+	if now.Sub(p.idleSince) >= p.idleTimeout {
+		log.Printf("[POOL] idle for %s, shrinking from %d to %d workers", p.idleTimeout, p.live, p.live-1)
+		p.resize(p.live - 1)
+		p.idleSince = now
+	}
+}
+
+// resize starts or retires workers until n of them are live, it must be called while holding the lock.
+// A retired worker leaves once it is done with its current task, a retirement which no worker has taken
+// yet is withdrawn before a new worker is started.
+func (p *Pool) resize(n int) {
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	for p.live < n {
+		select {
+		case <-p.retire:
+		default:
+			p.nextWorkerID++
+			p.wg.Add(1)
+			go p.worker(p.nextWorkerID, p.deps)
+		}
+		p.live++
+	}
+
+	for p.live > n {
+		p.retire <- struct{}{}
+		p.live--
+	}
+}
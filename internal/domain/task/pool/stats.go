@@ -0,0 +1,78 @@
+package pool
+
+import "time"
+
+// throughputWindow is the span the throughput of the pool is averaged over
+const throughputWindow = 60
+
+// Stats is a point in time view of the pool, Throughput is the number of tasks processed per second
+// over the last minute and Panics counts the panics recovered from handlers and workers
+type Stats struct {
+	MinWorkers    int
+	MaxWorkers    int
+	TargetWorkers int
+	Workers       int
+	BusyWorkers   int
+	QueueLength   int
+	QueueCapacity int
+	Retrying      int
+	Paused        bool
+	Draining      bool
+	Processed     int64
+	Throughput    float64
+	Panics        int64
+}
+
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return Stats{
+		MinWorkers:    p.minWorkers,
+		MaxWorkers:    p.maxWorkers,
+		TargetWorkers: p.target,
+		Workers:       p.live,
+		BusyWorkers:   len(p.running),
+		QueueLength:   p.queue.Len(),
+		QueueCapacity: p.size,
+		Retrying:      len(p.retrying),
+		Paused:        p.resumed != nil,
+		Draining:      p.draining,
+		Processed:     p.meter.total,
+		Throughput:    p.meter.rate(time.Now()),
+		Panics:        p.panics,
+	}
+}
+
+// meter counts the processed tasks in one bucket per second of the throughput window
+type meter struct {
+	total   int64
+	counts  [throughputWindow]int64
+	seconds [throughputWindow]int64
+}
+
+func (m *meter) mark(now time.Time) {
+	second := now.Unix()
+	i := second % throughputWindow
+
+	if m.seconds[i] != second {
+		m.seconds[i] = second
+		m.counts[i] = 0
+	}
+
+	m.counts[i]++
+	m.total++
+}
+
+func (m *meter) rate(now time.Time) float64 {
+	var sum int64
+	second := now.Unix()
+
+	for i := range m.counts {
+		if second-m.seconds[i] < throughputWindow {
+			sum += m.counts[i]
+		}
+	}
+
+	return float64(sum) / throughputWindow
+}
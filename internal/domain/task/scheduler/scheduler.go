@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+)
+
+const (
+	defaultInterval = time.Second
+	defaultBatch    = 100
+)
+
+// Submitter is the part of the pool the scheduler hands due tasks to
+type Submitter interface {
+	Submit(task *entity.Task) error
+}
+
+type Deps struct {
+	TaskService task.TaskService
+	Pool        Submitter
+}
+
+// Scheduler moves the scheduled tasks whose run_at has passed to pending and submits them to the pool.
+// The due rows are read from the database on every tick, so scheduled work survives restarts and
+// several executors can share the same tasks table.
+type Scheduler struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	deps     Deps
+	interval time.Duration
+	batch    int
+}
+
+func New(parent context.Context, interval time.Duration) *Scheduler {
+	ctx, cancel := context.WithCancel(parent)
+
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &Scheduler{
+		ctx:      ctx,
+		cancel:   cancel,
+		interval: interval,
+		batch:    defaultBatch,
+	}
+}
+
+func (s *Scheduler) Start(deps Deps) {
+	log.Printf("[SCHEDULER] starting, checking due tasks every %s", s.interval)
+	s.deps = deps
+
+	s.wg.Add(1)
+	go s.run()
+}
+
+func (s *Scheduler) Shutdown() {
+	log.Println("[SCHEDULER] shutdown initiated")
+	s.cancel()
+	s.wg.Wait()
+	log.Println("[SCHEDULER] shutdown completed")
+}
+
+func (s *Scheduler) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.promote()
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// promote drains every due task in batches, a task the pool can not take right now is already
+// pending in the database and is claimed by the pool later
+func (s *Scheduler) promote() {
+	for s.ctx.Err() == nil {
+		tasks, err := s.deps.TaskService.PromoteDue(s.ctx, s.batch)
+		if err != nil || len(tasks) == 0 {
+			return
+		}
+
+		for i := range tasks {
+			if err := s.deps.Pool.Submit(&tasks[i]); err != nil {
+				log.Printf("[SCHEDULER] due task %s is left to the durable backlog: %v", tasks[i].Id, err)
+			}
+		}
+		log.Printf("[SCHEDULER] %d scheduled tasks are due", len(tasks))
+
+		if len(tasks) < s.batch {
+			return
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
+)
+
+type fakeTaskService struct {
+	task.TaskService
+
+	mu  sync.Mutex
+	due []entity.Task
+}
+
+func (f *fakeTaskService) PromoteDue(ctx context.Context, limit int) ([]entity.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := min(limit, len(f.due))
+	promoted := f.due[:n]
+	f.due = f.due[n:]
+	for i := range promoted {
+		promoted[i].Status = entity.StatusPending
+	}
+	return promoted, nil
+}
+
+type fakePool struct {
+	mu        sync.Mutex
+	submitted []uuid.UUID
+	full      bool
+}
+
+func (f *fakePool) Submit(task *entity.Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.full {
+		return errors.New("pool is full")
+	}
+	f.submitted = append(f.submitted, task.Id)
+	return nil
+}
+
+func (f *fakePool) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.submitted)
+}
+
+func scheduledTasks(n int) []entity.Task {
+	tasks := make([]entity.Task, 0, n)
+	for i := 0; i < n; i++ {
+		runAt := time.Now().Add(-time.Second)
+		tasks = append(tasks, entity.Task{
+			UniversalModel: db.UniversalModel{Id: uuid.New()},
+			Status:         entity.StatusScheduled,
+			RunAt:          &runAt,
+		})
+	}
+	return tasks
+}
+
+func TestScheduler_SubmitsDueTasks(t *testing.T) {
+	svc := &fakeTaskService{due: scheduledTasks(3)}
+	pool := &fakePool{}
+
+	s := New(context.Background(), 10*time.Millisecond)
+	s.Start(Deps{TaskService: svc, Pool: pool})
+	defer s.Shutdown()
+
+	assert.Eventually(t, func() bool {
+		return pool.count() == 3
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestScheduler_DrainsInBatches(t *testing.T) {
+	svc := &fakeTaskService{due: scheduledTasks(5)}
+	pool := &fakePool{}
+
+	s := New(context.Background(), time.Hour)
+	s.batch = 2
+	s.deps = Deps{TaskService: svc, Pool: pool}
+	s.promote()
+
+	assert.Equal(t, 5, pool.count())
+}
+
+func TestScheduler_FullPoolKeepsPromoting(t *testing.T) {
+	svc := &fakeTaskService{due: scheduledTasks(2)}
+	pool := &fakePool{full: true}
+
+	s := New(context.Background(), time.Hour)
+	s.deps = Deps{TaskService: svc, Pool: pool}
+	s.promote()
+
+	assert.Empty(t, svc.due)
+	assert.Equal(t, 0, pool.count())
+}
@@ -3,24 +3,91 @@ package task
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
 	taskInterface "github.com/thealiakbari/task-pool-system/internal/ports/inbound/task"
 	"github.com/thealiakbari/task-pool-system/internal/ports/outbound/task"
+	"github.com/thealiakbari/task-pool-system/pkg/common/db"
 	"github.com/thealiakbari/task-pool-system/pkg/common/logger"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
+	"github.com/thealiakbari/task-pool-system/pkg/common/store"
 )
 
+var (
+	ErrEmptyId = &appErr.Error{
+		Cause:   errors.New("id must not be empty"),
+		Message: "id must not be empty",
+		Class:   appErr.EBadArg,
+	}
+	ErrTaskNotFound = &appErr.Error{
+		Cause:   errors.New("task not found"),
+		Message: "task not found",
+		Class:   appErr.ENotFound,
+	}
+	ErrTaskNotCancellable = &appErr.Error{
+		Cause:   errors.New("task is already finished and can not be cancelled"),
+		Message: "task is already finished and can not be cancelled",
+		Class:   appErr.EConflict,
+	}
+	ErrTaskNotDeadLettered = &appErr.Error{
+		Cause:   errors.New("only dead lettered tasks can be re-driven"),
+		Message: "only dead lettered tasks can be re-driven",
+		Class:   appErr.EConflict,
+	}
+	ErrUnknownEvent = &appErr.Error{
+		Cause:   errors.New("event not found"),
+		Message: "the last event id matches no recorded event",
+		Class:   appErr.EBadArg,
+	}
+	ErrUnknownTaskType = &appErr.Error{
+		Cause:   handler.ErrUnknownType,
+		Message: "no handler is registered for the task type",
+		Class:   appErr.EValidation,
+	}
+)
+
+// sortColumns maps the public sort keys to the tasks table columns
+var sortColumns = map[string]string{
+	"createdAt":  "created_at",
+	"updatedAt":  "updated_at",
+	"title":      "title",
+	"status":     "status",
+	"duration":   "duration",
+	"priority":   "priority",
+	"runAt":      "run_at",
+	"queuedAt":   "queued_at",
+	"startedAt":  "started_at",
+	"finishedAt": "finished_at",
+}
+
 type TaskConfig struct {
 	Logger   logger.Logger
 	TaskRepo task.TaskRepository
+	// Handlers is used to reject tasks which no worker can run, the type is not checked when it is nil
+	Handlers *handler.Registry
+	// Publishers receive every recorded event once the transaction which recorded it commits
+	Publishers []task.EventPublisher
 }
 
+// idempotencyCacheSize bounds the idempotency keys kept in memory, the database holds all of them
+const idempotencyCacheSize = 10000
+
 type taskService struct {
 	TaskConfig
+	keys *store.TTLStore[entity.IdempotencyKey]
 }
 
 func NewTaskService(config TaskConfig) taskInterface.TaskService {
-	u := taskService{config}
+	u := taskService{
+		TaskConfig: config,
+		keys:       store.NewTTLStore[entity.IdempotencyKey](idempotencyCacheSize),
+	}
 	u.Logger = config.Logger.ForService(u)
 	return u
 }
@@ -31,33 +98,80 @@ func (u taskService) Create(ctx context.Context, req entity.Task) (res entity.Ta
 		return entity.Task{}, err
 	}
 
+	if u.Handlers != nil && !u.Handlers.Has(req.Type) {
+		u.Logger.Warnf(ctx, "unknown task type:%s", req.Type)
+		return entity.Task{}, ErrUnknownTaskType
+	}
+
+	if req.Status == entity.StatusPending && req.QueuedAt == nil {
+		now := time.Now()
+		req.QueuedAt = &now
+	}
+
 	taskEntity, err := u.TaskRepo.Create(ctx, req)
 	if err != nil {
 		u.Logger.Errorf(ctx, "Cannot create task item: %v", err)
 		return entity.Task{}, err
 	}
 
+	err = u.recordEvents(ctx, entity.NewTaskEvent(taskEntity, "", entity.Transition{Reason: "created"}))
+	if err != nil {
+		return entity.Task{}, err
+	}
+
 	return taskEntity, nil
 }
 
+// Update saves the whole task, its status change must be allowed by the state machine
 func (u taskService) Update(ctx context.Context, req entity.Task) (res entity.Task, err error) {
+	return u.transition(ctx, req, entity.Transition{Reason: "updated"})
+}
+
+// Transition saves the state of the task, a status change must be allowed by the state machine and is recorded
+// with the worker and the reason of the transition. Only the state columns are written, the caller's copy of
+// the rest of the task may be older than the stored one.
+func (u taskService) Transition(ctx context.Context, req entity.Task, transition entity.Transition) (res entity.Task, err error) {
+	return u.transition(ctx, req, transition, entity.StateColumns...)
+}
+
+func (u taskService) transition(ctx context.Context, req entity.Task, transition entity.Transition, columns ...string) (res entity.Task, err error) {
 	if err = req.Validate(ctx); err != nil {
 		u.Logger.Warnf(ctx, "validation error:%v", err)
 		return entity.Task{}, err
 	}
 
-	err = u.TaskRepo.Update(ctx, req)
+	current, err := u.TaskRepo.FindByIdOrEmpty(ctx, req.Id.String())
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if current.Id == uuid.Nil {
+		return entity.Task{}, ErrTaskNotFound
+	}
+
+	if !current.Status.CanTransitionTo(req.Status) {
+		u.Logger.Warnf(ctx, "task %s can not move from %s to %s", req.Id, current.Status, req.Status)
+		return entity.Task{}, entity.ErrInvalidStateTransition
+	}
+
+	err = u.save(ctx, req, current.Status, columns...)
 	if err != nil {
 		return entity.Task{}, err
 	}
 
+	if current.Status != req.Status {
+		err = u.recordEvents(ctx, entity.NewTaskEvent(req, current.Status, transition))
+		if err != nil {
+			return entity.Task{}, err
+		}
+	}
+
 	return req, nil
 }
 
 func (u taskService) GetByIdOrEmpty(ctx context.Context, id string) (res entity.Task, err error) {
 	if id == "" {
-		err = errors.New("id must not be empty")
-		return entity.Task{}, err
+		return entity.Task{}, ErrEmptyId
 	}
 
 	taskEntity, err := u.TaskRepo.FindByIdOrEmpty(ctx, id)
@@ -68,10 +182,157 @@ func (u taskService) GetByIdOrEmpty(ctx context.Context, id string) (res entity.
 	return taskEntity, nil
 }
 
+// Events returns the task along with its recorded status transitions, oldest first
+func (u taskService) Events(ctx context.Context, id string) (task entity.Task, res []entity.TaskEvent, err error) {
+	task, err = u.GetByIdOrEmpty(ctx, id)
+	if err != nil {
+		return entity.Task{}, nil, err
+	}
+
+	if task.Id == uuid.Nil {
+		return entity.Task{}, nil, ErrTaskNotFound
+	}
+
+	res, err = u.TaskRepo.FindEvents(ctx, id)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot find events of task %s: %v", id, err)
+		return entity.Task{}, nil, err
+	}
+
+	return task, res, nil
+}
+
+// EventsAfter returns up to limit events which match the filter and happened after the event with the given id,
+// oldest first. It fails with ErrUnknownEvent when no event has the id, nothing could be told to follow it.
+func (u taskService) EventsAfter(ctx context.Context, filter entity.EventFilter, after string, limit int) (res []entity.TaskEvent, err error) {
+	if after == "" {
+		return nil, ErrEmptyId
+	}
+
+	res, err = u.TaskRepo.FindEventsAfter(ctx, filter, after, limit)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot find events after %s: %v", after, err)
+		return nil, err
+	}
+
+	if len(res) > 0 {
+		return res, nil
+	}
+
+	event, err := u.TaskRepo.FindEventByIdOrEmpty(ctx, after)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot find event %s: %v", after, err)
+		return nil, err
+	}
+
+	if event.Id == uuid.Nil {
+		return nil, ErrUnknownEvent
+	}
+
+	return res, nil
+}
+
+func (u taskService) Cancel(ctx context.Context, id string) (res entity.Task, err error) {
+	taskEntity, err := u.GetByIdOrEmpty(ctx, id)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if taskEntity.Id == uuid.Nil {
+		return entity.Task{}, ErrTaskNotFound
+	}
+
+	if taskEntity.Status == entity.StatusCancelled {
+		return taskEntity, nil
+	}
+
+	from := taskEntity.Status
+	if err = taskEntity.TransitionTo(entity.StatusCancelled); err != nil {
+		return entity.Task{}, ErrTaskNotCancellable
+	}
+
+	now := time.Now()
+	taskEntity.FinishedAt = &now
+
+	err = u.save(ctx, taskEntity, from, entity.StateColumns...)
+	if errors.Is(err, entity.ErrInvalidStateTransition) {
+		return entity.Task{}, ErrTaskNotCancellable
+	}
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot cancel task %s: %v", id, err)
+		return entity.Task{}, err
+	}
+
+	err = u.recordEvents(ctx, entity.NewTaskEvent(taskEntity, from, entity.Transition{Reason: "cancelled on request"}))
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	return taskEntity, nil
+}
+
+// Redrive moves a dead lettered task back to the pending state with a fresh attempt budget
+func (u taskService) Redrive(ctx context.Context, id string) (res entity.Task, err error) {
+	taskEntity, err := u.GetByIdOrEmpty(ctx, id)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if taskEntity.Id == uuid.Nil {
+		return entity.Task{}, ErrTaskNotFound
+	}
+
+	if taskEntity.Status != entity.StatusDeadLetter {
+		return entity.Task{}, ErrTaskNotDeadLettered
+	}
+
+	if err = taskEntity.TransitionTo(entity.StatusPending); err != nil {
+		return entity.Task{}, err
+	}
+
+	now := time.Now()
+	taskEntity.Attempts = 0
+	taskEntity.Error = ""
+	taskEntity.QueuedAt = &now
+	err = u.save(ctx, taskEntity, entity.StatusDeadLetter, entity.StateColumns...)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot re-drive task %s: %v", id, err)
+		return entity.Task{}, err
+	}
+
+	err = u.recordEvents(ctx, entity.NewTaskEvent(taskEntity, entity.StatusDeadLetter, entity.Transition{Reason: "re-driven on request"}))
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	return taskEntity, nil
+}
+
+func (u taskService) List(ctx context.Context, filter entity.TaskFilter, portion request.Portion) (res []entity.Task, total int64, err error) {
+	query := filterQuery(filter)
+
+	total, err = u.TaskRepo.FilterCount(ctx, query)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot count tasks: %v", err)
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return []entity.Task{}, 0, nil
+	}
+
+	res, err = u.TaskRepo.FilterFind(ctx, query, filterOrder(filter), portion.Limit, portion.Offset)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot find tasks: %v", err)
+		return nil, 0, err
+	}
+
+	return res, total, nil
+}
+
 func (u taskService) Purge(ctx context.Context, id string) (err error) {
 	if id == "" {
-		err := errors.New("id must not be empty")
-		return err
+		return ErrEmptyId
 	}
 
 	err = u.TaskRepo.Purge(ctx, id)
@@ -84,8 +345,7 @@ func (u taskService) Purge(ctx context.Context, id string) (err error) {
 
 func (u taskService) Delete(ctx context.Context, id string) (err error) {
 	if id == "" {
-		err := errors.New("id must not be empty")
-		return err
+		return ErrEmptyId
 	}
 
 	err = u.TaskRepo.Delete(ctx, id)
@@ -96,16 +356,250 @@ func (u taskService) Delete(ctx context.Context, id string) (err error) {
 	return nil
 }
 
-func (u taskService) GetByPhoneNumberOrEmpty(ctx context.Context, phoneNumber string) (res entity.Task, err error) {
-	if phoneNumber == "" {
-		err := errors.New("phoneNumber must not be empty")
-		return entity.Task{}, err
+func (u taskService) ClaimPending(ctx context.Context, owner string, limit int, ttl time.Duration) (res []entity.Task, err error) {
+	if limit <= 0 {
+		return []entity.Task{}, nil
 	}
 
-	taskEntity, err := u.TaskRepo.FindByPhoneNumberOrEmpty(ctx, phoneNumber)
+	res, err = u.TaskRepo.ClaimPending(ctx, owner, limit, ttl)
 	if err != nil {
-		return entity.Task{}, err
+		u.Logger.Errorf(ctx, "Cannot claim pending tasks: %v", err)
+		return nil, err
 	}
 
-	return taskEntity, nil
+	return res, nil
+}
+
+func (u taskService) Lease(ctx context.Context, id uuid.UUID, owner string, ttl time.Duration) (ok bool, err error) {
+	ok, err = u.TaskRepo.Lease(ctx, id, owner, ttl)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot lease task %s: %v", id, err)
+		return false, err
+	}
+
+	return ok, nil
+}
+
+func (u taskService) RenewLeases(ctx context.Context, owner string, ids []uuid.UUID, ttl time.Duration) (err error) {
+	err = u.TaskRepo.RenewLeases(ctx, owner, ids, ttl)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot renew task leases: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u taskService) ReleaseLeases(ctx context.Context, owner string) (err error) {
+	err = u.TaskRepo.ReleaseLeases(ctx, owner)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot release task leases: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindIdempotencyKey returns the stored key or an empty one, the keys read from the database are cached
+// until they expire, a key is never cached before its transaction commits
+func (u taskService) FindIdempotencyKey(ctx context.Context, key string) (res entity.IdempotencyKey, err error) {
+	if cached, ok := u.keys.Get(key); ok {
+		return cached, nil
+	}
+
+	res, err = u.TaskRepo.FindIdempotencyKey(ctx, key)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot find idempotency key: %v", err)
+		return entity.IdempotencyKey{}, err
+	}
+
+	if res.Key != "" {
+		u.keys.Set(key, res, res.ExpiresAt)
+	}
+
+	return res, nil
+}
+
+func (u taskService) SaveIdempotencyKey(ctx context.Context, in entity.IdempotencyKey) (err error) {
+	ok, err := u.TaskRepo.SaveIdempotencyKey(ctx, in)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot save idempotency key: %v", err)
+		return err
+	}
+
+	if !ok {
+		u.Logger.Warnf(ctx, "idempotency key %s is used by a request in progress", in.Key)
+		return entity.ErrIdempotencyKeyInUse
+	}
+
+	return nil
+}
+
+func (u taskService) ReclaimExpired(ctx context.Context) (res int64, err error) {
+	tasks, err := u.TaskRepo.ReclaimExpired(ctx)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot reclaim expired tasks: %v", err)
+		return 0, err
+	}
+
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+
+	u.Logger.Warnf(ctx, "%d running tasks lost their lease and were moved back to pending", len(tasks))
+	events := make([]entity.TaskEvent, 0, len(tasks))
+	for _, v := range tasks {
+		events = append(events, entity.NewTaskEvent(v, entity.StatusRunning, entity.Transition{Reason: "lease expired"}))
+	}
+
+	err = u.recordEvents(ctx, events...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(tasks)), nil
+}
+
+func (u taskService) PromoteDue(ctx context.Context, limit int) (res []entity.Task, err error) {
+	if limit <= 0 {
+		return []entity.Task{}, nil
+	}
+
+	res, err = u.TaskRepo.PromoteDue(ctx, limit)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot promote due scheduled tasks: %v", err)
+		return nil, err
+	}
+
+	events := make([]entity.TaskEvent, 0, len(res))
+	for _, v := range res {
+		events = append(events, entity.NewTaskEvent(v, entity.StatusScheduled, entity.Transition{Reason: "run time reached"}))
+	}
+
+	err = u.recordEvents(ctx, events...)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// save stores the task unless another writer moved it away from the status it was read in, the state machine
+// check of the caller then holds for the stored row as well
+func (u taskService) save(ctx context.Context, in entity.Task, from entity.Status, columns ...string) (err error) {
+	ok, err := u.TaskRepo.UpdateFrom(ctx, in, from, columns...)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		u.Logger.Warnf(ctx, "task %s was moved from %s by another writer", in.Id, from)
+		return entity.ErrInvalidStateTransition
+	}
+
+	return nil
+}
+
+// recordEvents stores the events and publishes them once the transaction of ctx commits, or right away
+// when there is none
+func (u taskService) recordEvents(ctx context.Context, events ...entity.TaskEvent) (err error) {
+	err = u.TaskRepo.CreateEvents(ctx, events)
+	if err != nil {
+		u.Logger.Errorf(ctx, "Cannot record task events: %v", err)
+		return err
+	}
+
+	if len(u.Publishers) == 0 {
+		return nil
+	}
+
+	db.AfterCommit(ctx, func() {
+		for _, event := range events {
+			for _, publisher := range u.Publishers {
+				publisher.Publish(event)
+			}
+		}
+	})
+
+	return nil
+}
+
+// filterQuery builds the `[]any{condition, args...}` form which is expected by the repository filters
+func filterQuery(filter entity.TaskFilter) []any {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+
+	if len(filter.Ids) > 0 {
+		conditions = append(conditions, "id IN ?")
+		args = append(args, filter.Ids)
+	}
+
+	if len(filter.Titles) > 0 {
+		titles := make([]string, 0, len(filter.Titles))
+		for _, title := range filter.Titles {
+			titles = append(titles, "title ILIKE ?")
+			args = append(args, "%"+title+"%")
+		}
+		conditions = append(conditions, "("+strings.Join(titles, " OR ")+")")
+	}
+
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, "status IN ?")
+		args = append(args, filter.Statuses)
+	}
+
+	if len(filter.Types) > 0 {
+		conditions = append(conditions, "type IN ?")
+		args = append(args, filter.Types)
+	}
+
+	if filter.CreatedAt.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *filter.CreatedAt.From)
+	}
+
+	if filter.CreatedAt.To != nil {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, *filter.CreatedAt.To)
+	}
+
+	if filter.UpdatedAt.From != nil {
+		conditions = append(conditions, "updated_at >= ?")
+		args = append(args, *filter.UpdatedAt.From)
+	}
+
+	if filter.UpdatedAt.To != nil {
+		conditions = append(conditions, "updated_at <= ?")
+		args = append(args, *filter.UpdatedAt.To)
+	}
+
+	if filter.Duration.From != nil {
+		conditions = append(conditions, "duration >= ?")
+		args = append(args, *filter.Duration.From)
+	}
+
+	if filter.Duration.To != nil {
+		conditions = append(conditions, "duration <= ?")
+		args = append(args, *filter.Duration.To)
+	}
+
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	return append([]any{strings.Join(conditions, " AND ")}, args...)
+}
+
+func filterOrder(filter entity.TaskFilter) string {
+	column, ok := sortColumns[filter.SortBy]
+	if !ok {
+		column = sortColumns["createdAt"]
+	}
+
+	sortType := request.SortTypeDESC
+	if strings.ToUpper(filter.SortType) == request.SortTypeASC {
+		sortType = request.SortTypeASC
+	}
+
+	return fmt.Sprintf("%s %s, id %s", column, sortType, sortType)
 }
@@ -4,11 +4,16 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/internal/domain/task/handler"
+	"github.com/thealiakbari/task-pool-system/internal/ports/outbound/task"
 	"github.com/thealiakbari/task-pool-system/pkg/common/logger"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
 	appErr "github.com/thealiakbari/task-pool-system/pkg/common/response"
 )
 
@@ -26,6 +31,11 @@ func (m *mockRepo) Update(ctx context.Context, in entity.Task) error {
 	return args.Error(0)
 }
 
+func (m *mockRepo) UpdateFrom(ctx context.Context, in entity.Task, from entity.Status, columns ...string) (bool, error) {
+	args := m.Called(ctx, in, from, columns)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *mockRepo) FindByIds(ctx context.Context, ids []string) ([]entity.Task, error) {
 	args := m.Called(ctx, ids)
 	return args.Get(0).([]entity.Task), args.Error(1)
@@ -56,6 +66,75 @@ func (m *mockRepo) FilterCount(ctx context.Context, query []any) (int64, error)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *mockRepo) ClaimPending(ctx context.Context, owner string, limit int, ttl time.Duration) ([]entity.Task, error) {
+	args := m.Called(ctx, owner, limit, ttl)
+	return args.Get(0).([]entity.Task), args.Error(1)
+}
+
+func (m *mockRepo) Lease(ctx context.Context, id uuid.UUID, owner string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, id, owner, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockRepo) RenewLeases(ctx context.Context, owner string, ids []uuid.UUID, ttl time.Duration) error {
+	args := m.Called(ctx, owner, ids, ttl)
+	return args.Error(0)
+}
+
+func (m *mockRepo) FindIdempotencyKey(ctx context.Context, key string) (entity.IdempotencyKey, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(entity.IdempotencyKey), args.Error(1)
+}
+
+func (m *mockRepo) SaveIdempotencyKey(ctx context.Context, in entity.IdempotencyKey) (bool, error) {
+	args := m.Called(ctx, in)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockRepo) ReleaseLeases(ctx context.Context, owner string) error {
+	args := m.Called(ctx, owner)
+	return args.Error(0)
+}
+
+func (m *mockRepo) ReclaimExpired(ctx context.Context) ([]entity.Task, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entity.Task), args.Error(1)
+}
+
+func (m *mockRepo) PromoteDue(ctx context.Context, limit int) ([]entity.Task, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]entity.Task), args.Error(1)
+}
+
+func (m *mockRepo) FindEvents(ctx context.Context, taskId string) ([]entity.TaskEvent, error) {
+	args := m.Called(ctx, taskId)
+	return args.Get(0).([]entity.TaskEvent), args.Error(1)
+}
+
+func (m *mockRepo) FindEventsAfter(ctx context.Context, filter entity.EventFilter, after string, limit int) ([]entity.TaskEvent, error) {
+	args := m.Called(ctx, filter, after, limit)
+	return args.Get(0).([]entity.TaskEvent), args.Error(1)
+}
+
+func (m *mockRepo) FindEventByIdOrEmpty(ctx context.Context, id string) (entity.TaskEvent, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.TaskEvent), args.Error(1)
+}
+
+func (m *mockRepo) CreateEvents(ctx context.Context, in []entity.TaskEvent) error {
+	args := m.Called(ctx, in)
+	return args.Error(0)
+}
+
+// fakePublisher records the published events
+type fakePublisher struct {
+	events []entity.TaskEvent
+}
+
+func (f *fakePublisher) Publish(event entity.TaskEvent) {
+	f.events = append(f.events, event)
+}
+
 func TestCreate_Success(t *testing.T) {
 	ctx := context.Background()
 	repo := new(mockRepo)
@@ -71,6 +150,7 @@ func TestCreate_Success(t *testing.T) {
 
 	item := entity.Task{Title: "test", Description: "test"}
 	repo.On("Create", ctx, item).Return(item, nil)
+	repo.On("CreateEvents", ctx, mock.Anything).Return(nil)
 
 	res, err := service.Create(ctx, item)
 	assert.NoError(t, err)
@@ -99,6 +179,30 @@ func TestCreate_ValidationError(t *testing.T) {
 	assert.Equal(t, entity.Task{}, res)
 }
 
+func TestCreate_UnknownType(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	handlers := handler.NewRegistry()
+	handlers.MustRegister(handler.TypeSleep, handler.Sleep())
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+		Handlers: handlers,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Type: "email"}
+
+	_, err = service.Create(ctx, item)
+	assert.ErrorIs(t, err, ErrUnknownTaskType)
+	assert.True(t, appErr.IsValidation(err))
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestCreate_RepoError(t *testing.T) {
 	ctx := context.Background()
 	repo := new(mockRepo)
@@ -197,3 +301,570 @@ func TestDelete_Success(t *testing.T) {
 	err = service.Delete(ctx, "123")
 	assert.NoError(t, err)
 }
+
+func TestList_Success(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	filter := entity.TaskFilter{
+		Statuses: []entity.Status{entity.StatusPending},
+		Titles:   []string{"report"},
+		SortBy:   "title",
+		SortType: "ASC",
+	}
+	query := []any{"(title ILIKE ?) AND status IN ?", "%report%", []entity.Status{entity.StatusPending}}
+	expected := []entity.Task{{Title: "report", Description: "test"}}
+	repo.On("FilterCount", ctx, query).Return(int64(1), nil)
+	repo.On("FilterFind", ctx, query, "title ASC, id ASC", 10, 20).Return(expected, nil)
+
+	res, total, err := service.List(ctx, filter, request.Portion{Limit: 10, Offset: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, expected, res)
+	repo.AssertExpectations(t)
+}
+
+func TestList_Empty(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	repo.On("FilterCount", ctx, []any(nil)).Return(int64(0), nil)
+
+	res, total, err := service.List(ctx, entity.TaskFilter{}, request.Portion{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, res)
+	repo.AssertNotCalled(t, "FilterFind", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCancel_Success(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusRunning}
+	item.Id = uuid.New()
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+	repo.On("UpdateFrom", ctx, mock.MatchedBy(func(in entity.Task) bool {
+		return in.Id == item.Id && in.Status == entity.StatusCancelled && in.FinishedAt != nil
+	}), entity.StatusRunning, entity.StateColumns).Return(true, nil)
+	repo.On("CreateEvents", ctx, mock.MatchedBy(func(events []entity.TaskEvent) bool {
+		return len(events) == 1 && events[0].FromStatus == entity.StatusRunning && events[0].ToStatus == entity.StatusCancelled
+	})).Return(nil)
+
+	res, err := service.Cancel(ctx, item.Id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, entity.StatusCancelled, res.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestCancel_AlreadyFinished(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusCompleted}
+	item.Id = uuid.New()
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+
+	_, err = service.Cancel(ctx, item.Id.String())
+	assert.ErrorIs(t, err, ErrTaskNotCancellable)
+	assert.True(t, appErr.IsConflict(err))
+	repo.AssertNotCalled(t, "UpdateFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRedrive_NotDeadLettered(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusFailed}
+	item.Id = uuid.New()
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+
+	_, err = service.Redrive(ctx, item.Id.String())
+	assert.ErrorIs(t, err, ErrTaskNotDeadLettered)
+	repo.AssertNotCalled(t, "UpdateFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPromoteDue_NoRoom(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	res, err := service.PromoteDue(ctx, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+	repo.AssertNotCalled(t, "PromoteDue", mock.Anything, mock.Anything)
+}
+
+func TestTransition_RecordsEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusRunning}
+	item.Id = uuid.New()
+	completed := item
+	completed.Status = entity.StatusCompleted
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+	repo.On("UpdateFrom", ctx, completed, entity.StatusRunning, entity.StateColumns).Return(true, nil)
+	repo.On("CreateEvents", ctx, mock.MatchedBy(func(events []entity.TaskEvent) bool {
+		return len(events) == 1 &&
+			events[0].TaskId == item.Id &&
+			events[0].FromStatus == entity.StatusRunning &&
+			events[0].ToStatus == entity.StatusCompleted &&
+			events[0].WorkerId == "pool/worker-1" &&
+			events[0].Reason == "completed"
+	})).Return(nil)
+
+	res, err := service.Transition(ctx, completed, entity.Transition{WorkerId: "pool/worker-1", Reason: "completed"})
+	assert.NoError(t, err)
+	assert.Equal(t, entity.StatusCompleted, res.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestTransition_SameStatusRecordsNoEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusPending}
+	item.Id = uuid.New()
+	renamed := item
+	renamed.Title = "renamed"
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+	repo.On("UpdateFrom", ctx, renamed, entity.StatusPending, []string(nil)).Return(true, nil)
+
+	_, err = service.Update(ctx, renamed)
+	assert.NoError(t, err)
+	repo.AssertNotCalled(t, "CreateEvents", mock.Anything, mock.Anything)
+}
+
+func TestTransition_LosesRaceToAnotherWriter(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusRunning}
+	item.Id = uuid.New()
+	completed := item
+	completed.Status = entity.StatusCompleted
+	// The task is cancelled between the read and the write
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+	repo.On("UpdateFrom", ctx, completed, entity.StatusRunning, entity.StateColumns).Return(false, nil)
+
+	_, err = service.Transition(ctx, completed, entity.Transition{Reason: "completed"})
+	assert.ErrorIs(t, err, entity.ErrInvalidStateTransition)
+	repo.AssertNotCalled(t, "CreateEvents", mock.Anything, mock.Anything)
+}
+
+func TestCancel_LosesRaceToWorker(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusRunning}
+	item.Id = uuid.New()
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+	repo.On("UpdateFrom", ctx, mock.Anything, entity.StatusRunning, entity.StateColumns).Return(false, nil)
+
+	_, err = service.Cancel(ctx, item.Id.String())
+	assert.ErrorIs(t, err, ErrTaskNotCancellable)
+	repo.AssertNotCalled(t, "CreateEvents", mock.Anything, mock.Anything)
+}
+
+func TestTransition_InvalidTransition(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusCancelled}
+	item.Id = uuid.New()
+	completed := item
+	completed.Status = entity.StatusCompleted
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+
+	_, err = service.Transition(ctx, completed, entity.Transition{Reason: "completed"})
+	assert.ErrorIs(t, err, entity.ErrInvalidStateTransition)
+	assert.True(t, appErr.IsConflict(err))
+	repo.AssertNotCalled(t, "UpdateFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusPending}
+	item.Id = uuid.New()
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(entity.Task{}, nil)
+
+	_, err = service.Update(ctx, item)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	repo.AssertNotCalled(t, "UpdateFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReclaimExpired_RecordsEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusPending}
+	item.Id = uuid.New()
+	repo.On("ReclaimExpired", ctx).Return([]entity.Task{item}, nil)
+	repo.On("CreateEvents", ctx, mock.MatchedBy(func(events []entity.TaskEvent) bool {
+		return len(events) == 1 && events[0].FromStatus == entity.StatusRunning && events[0].ToStatus == entity.StatusPending
+	})).Return(nil)
+
+	res, err := service.ReclaimExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res)
+	repo.AssertExpectations(t)
+}
+
+func TestEvents_NotFound(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	id := uuid.New().String()
+	repo.On("FindByIdOrEmpty", ctx, id).Return(entity.Task{}, nil)
+
+	_, _, err = service.Events(ctx, id)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	repo.AssertNotCalled(t, "FindEvents", mock.Anything, mock.Anything)
+}
+
+func TestEvents_Success(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusCompleted}
+	item.Id = uuid.New()
+	events := []entity.TaskEvent{
+		{TaskId: item.Id, ToStatus: entity.StatusPending},
+		{TaskId: item.Id, FromStatus: entity.StatusPending, ToStatus: entity.StatusRunning},
+	}
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+	repo.On("FindEvents", ctx, item.Id.String()).Return(events, nil)
+
+	res, resEvents, err := service.Events(ctx, item.Id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, item, res)
+	assert.Equal(t, events, resEvents)
+}
+
+func TestFindIdempotencyKey_CachesStoredKey(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	key := entity.NewIdempotencyKey("key-1", "hash", uuid.New(), 201, []byte(`{}`))
+	repo.On("FindIdempotencyKey", ctx, "key-1").Return(key, nil).Once()
+	repo.On("FindIdempotencyKey", ctx, "key-2").Return(entity.IdempotencyKey{}, nil).Twice()
+
+	for i := 0; i < 2; i++ {
+		var res entity.IdempotencyKey
+		res, err = service.FindIdempotencyKey(ctx, "key-1")
+		assert.NoError(t, err)
+		assert.Equal(t, key.TaskId, res.TaskId)
+		assert.True(t, res.Matches("hash"))
+
+		res, err = service.FindIdempotencyKey(ctx, "key-2")
+		assert.NoError(t, err)
+		assert.Empty(t, res.Key)
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestSaveIdempotencyKey_InUse(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	key := entity.NewIdempotencyKey("key-1", "hash", uuid.New(), 201, []byte(`{}`))
+	repo.On("SaveIdempotencyKey", ctx, key).Return(false, nil)
+
+	err = service.SaveIdempotencyKey(ctx, key)
+	assert.ErrorIs(t, err, entity.ErrIdempotencyKeyInUse)
+}
+
+func TestEventsAfter(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	filter := entity.EventFilter{Statuses: []entity.Status{entity.StatusCompleted}}
+	after := uuid.NewString()
+	events := []entity.TaskEvent{{Id: uuid.New(), ToStatus: entity.StatusCompleted}}
+	repo.On("FindEventsAfter", ctx, filter, after, 100).Return(events, nil)
+
+	res, err := service.EventsAfter(ctx, filter, after, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, events, res)
+
+	_, err = service.EventsAfter(ctx, filter, "", 100)
+	assert.ErrorIs(t, err, ErrEmptyId)
+}
+
+func TestEventsAfter_UnknownEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	service := NewTaskService(TaskConfig{
+		Logger:   log,
+		TaskRepo: repo,
+	})
+
+	filter := entity.EventFilter{TaskId: uuid.New()}
+	known, unknown := uuid.NewString(), uuid.NewString()
+	repo.On("FindEventsAfter", ctx, filter, mock.Anything, 100).Return([]entity.TaskEvent{}, nil)
+	repo.On("FindEventByIdOrEmpty", ctx, known).Return(entity.TaskEvent{Id: uuid.MustParse(known)}, nil)
+	repo.On("FindEventByIdOrEmpty", ctx, unknown).Return(entity.TaskEvent{}, nil)
+
+	// The client has seen every event already
+	res, err := service.EventsAfter(ctx, filter, known, 100)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+
+	_, err = service.EventsAfter(ctx, filter, unknown, 100)
+	assert.ErrorIs(t, err, ErrUnknownEvent)
+}
+
+func TestCancel_PublishesEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	events := &fakePublisher{}
+	service := NewTaskService(TaskConfig{
+		Logger:     log,
+		TaskRepo:   repo,
+		Publishers: []task.EventPublisher{events},
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusPending}
+	item.Id = uuid.New()
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+	repo.On("UpdateFrom", ctx, mock.Anything, entity.StatusPending, entity.StateColumns).Return(true, nil)
+	repo.On("CreateEvents", ctx, mock.Anything).Return(nil)
+
+	_, err = service.Cancel(ctx, item.Id.String())
+	assert.NoError(t, err)
+	assert.Len(t, events.events, 1)
+	assert.Equal(t, item.Id, events.events[0].TaskId)
+	assert.Equal(t, entity.StatusPending, events.events[0].FromStatus)
+	assert.Equal(t, entity.StatusCancelled, events.events[0].ToStatus)
+}
+
+func TestPromoteDue_PublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	events := &fakePublisher{}
+	service := NewTaskService(TaskConfig{
+		Logger:     log,
+		TaskRepo:   repo,
+		Publishers: []task.EventPublisher{events},
+	})
+
+	first := entity.Task{Status: entity.StatusPending}
+	first.Id = uuid.New()
+	second := entity.Task{Status: entity.StatusPending}
+	second.Id = uuid.New()
+	repo.On("PromoteDue", ctx, 10).Return([]entity.Task{first, second}, nil)
+	repo.On("CreateEvents", ctx, mock.Anything).Return(nil)
+
+	_, err = service.PromoteDue(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, events.events, 2)
+	assert.Equal(t, first.Id, events.events[0].TaskId)
+	assert.Equal(t, second.Id, events.events[1].TaskId)
+}
+
+func TestTransition_UnrecordedEventIsNotPublished(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockRepo)
+	log, err := logger.New(
+		"local",
+		"taskApp",
+		"taskApp",
+	)
+	events := &fakePublisher{}
+	service := NewTaskService(TaskConfig{
+		Logger:     log,
+		TaskRepo:   repo,
+		Publishers: []task.EventPublisher{events},
+	})
+
+	item := entity.Task{Title: "test", Description: "test", Status: entity.StatusRunning}
+	item.Id = uuid.New()
+	completed := item
+	completed.Status = entity.StatusCompleted
+	repo.On("FindByIdOrEmpty", ctx, item.Id.String()).Return(item, nil)
+	repo.On("UpdateFrom", ctx, completed, entity.StatusRunning, entity.StateColumns).Return(true, nil)
+	repo.On("CreateEvents", ctx, mock.Anything).Return(errors.New("db down"))
+
+	_, err = service.Transition(ctx, completed, entity.Transition{Reason: "completed"})
+	assert.Error(t, err)
+	assert.Empty(t, events.events)
+}
@@ -0,0 +1,24 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
+)
+
+type ScheduleService interface {
+	Create(ctx context.Context, entity entity.Schedule) (res entity.Schedule, err error)
+	Update(ctx context.Context, entity entity.Schedule) (res entity.Schedule, err error)
+	GetByIdOrEmpty(ctx context.Context, id string) (res entity.Schedule, err error)
+	List(ctx context.Context, filter entity.ScheduleFilter, portion request.Portion) (res []entity.Schedule, total int64, err error)
+	Pause(ctx context.Context, id string) (res entity.Schedule, err error)
+	Resume(ctx context.Context, id string) (res entity.Schedule, err error)
+	Delete(ctx context.Context, id string) (err error)
+	FindDue(ctx context.Context, now time.Time, limit int) (res []entity.Schedule, err error)
+	Advance(ctx context.Context, id uuid.UUID, from time.Time, next time.Time, lastRunAt *time.Time) (ok bool, err error)
+	SetLastTask(ctx context.Context, id uuid.UUID, taskId uuid.UUID) (err error)
+}
@@ -2,15 +2,32 @@ package task
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+	"github.com/thealiakbari/task-pool-system/pkg/common/request"
 )
 
 type TaskService interface {
 	Create(ctx context.Context, entity entity.Task) (res entity.Task, err error)
 	Update(ctx context.Context, entity entity.Task) (res entity.Task, err error)
+	Transition(ctx context.Context, entity entity.Task, transition entity.Transition) (res entity.Task, err error)
 	GetByIdOrEmpty(ctx context.Context, id string) (res entity.Task, err error)
+	List(ctx context.Context, filter entity.TaskFilter, portion request.Portion) (res []entity.Task, total int64, err error)
+	Events(ctx context.Context, id string) (task entity.Task, res []entity.TaskEvent, err error)
+	EventsAfter(ctx context.Context, filter entity.EventFilter, after string, limit int) (res []entity.TaskEvent, err error)
+	Cancel(ctx context.Context, id string) (res entity.Task, err error)
+	Redrive(ctx context.Context, id string) (res entity.Task, err error)
 	Delete(ctx context.Context, id string) (err error)
 	Purge(ctx context.Context, id string) (err error)
-	GetByPhoneNumberOrEmpty(ctx context.Context, phoneNumber string) (res entity.Task, err error)
+	ClaimPending(ctx context.Context, owner string, limit int, ttl time.Duration) (res []entity.Task, err error)
+	Lease(ctx context.Context, id uuid.UUID, owner string, ttl time.Duration) (ok bool, err error)
+	RenewLeases(ctx context.Context, owner string, ids []uuid.UUID, ttl time.Duration) (err error)
+	ReleaseLeases(ctx context.Context, owner string) (err error)
+	FindIdempotencyKey(ctx context.Context, key string) (res entity.IdempotencyKey, err error)
+	SaveIdempotencyKey(ctx context.Context, in entity.IdempotencyKey) (err error)
+	ReclaimExpired(ctx context.Context) (res int64, err error)
+	PromoteDue(ctx context.Context, limit int) (res []entity.Task, err error)
 }
@@ -0,0 +1,22 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/thealiakbari/task-pool-system/internal/domain/schedule/entity"
+)
+
+type ScheduleRepository interface {
+	Create(ctx context.Context, in entity.Schedule) (res entity.Schedule, err error)
+	Update(ctx context.Context, in entity.Schedule) (err error)
+	FindByIdOrEmpty(ctx context.Context, id string) (res entity.Schedule, err error)
+	Delete(ctx context.Context, id string) (err error)
+	FilterFind(ctx context.Context, query []any, order string, limit int, offset int) (res []entity.Schedule, err error)
+	FilterCount(ctx context.Context, query []any) (res int64, err error)
+	FindDue(ctx context.Context, now time.Time, limit int) (res []entity.Schedule, err error)
+	Advance(ctx context.Context, id uuid.UUID, from time.Time, next time.Time, lastRunAt *time.Time) (ok bool, err error)
+	SetLastTask(ctx context.Context, id uuid.UUID, taskId uuid.UUID) (err error)
+}
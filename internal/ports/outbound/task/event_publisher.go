@@ -0,0 +1,8 @@
+package task
+
+import "github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
+
+// EventPublisher receives the recorded task events once they are committed, it must not block
+type EventPublisher interface {
+	Publish(event entity.TaskEvent)
+}
@@ -2,6 +2,9 @@ package task
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/thealiakbari/task-pool-system/internal/domain/task/entity"
 )
@@ -9,10 +12,23 @@ import (
 type TaskRepository interface {
 	Create(ctx context.Context, in entity.Task) (res entity.Task, err error)
 	Update(ctx context.Context, in entity.Task) (err error)
+	UpdateFrom(ctx context.Context, in entity.Task, from entity.Status, columns ...string) (ok bool, err error)
 	FindByIds(ctx context.Context, ids []string) (res []entity.Task, err error)
 	FindByIdOrEmpty(ctx context.Context, id string) (res entity.Task, err error)
 	Purge(ctx context.Context, id string) (err error)
 	Delete(ctx context.Context, id string) (err error)
 	FilterFind(ctx context.Context, query []any, order string, limit int, offset int) (res []entity.Task, err error)
 	FilterCount(ctx context.Context, query []any) (res int64, err error)
+	ClaimPending(ctx context.Context, owner string, limit int, ttl time.Duration) (res []entity.Task, err error)
+	Lease(ctx context.Context, id uuid.UUID, owner string, ttl time.Duration) (ok bool, err error)
+	RenewLeases(ctx context.Context, owner string, ids []uuid.UUID, ttl time.Duration) (err error)
+	ReleaseLeases(ctx context.Context, owner string) (err error)
+	ReclaimExpired(ctx context.Context) (res []entity.Task, err error)
+	PromoteDue(ctx context.Context, limit int) (res []entity.Task, err error)
+	CreateEvents(ctx context.Context, in []entity.TaskEvent) (err error)
+	FindIdempotencyKey(ctx context.Context, key string) (res entity.IdempotencyKey, err error)
+	SaveIdempotencyKey(ctx context.Context, in entity.IdempotencyKey) (ok bool, err error)
+	FindEvents(ctx context.Context, taskId string) (res []entity.TaskEvent, err error)
+	FindEventsAfter(ctx context.Context, filter entity.EventFilter, after string, limit int) (res []entity.TaskEvent, err error)
+	FindEventByIdOrEmpty(ctx context.Context, id string) (res entity.TaskEvent, err error)
 }
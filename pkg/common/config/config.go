@@ -2,7 +2,6 @@ package config
 
 import (
 	"bytes"
-	"fmt"
 	"log"
 	"os"
 	"strings"
@@ -121,7 +120,7 @@ func NewConfig(path string, internalSection interface{}) *Config {
 func (s *FileConfig) GetValue() string {
 	apiKey, err := os.ReadFile(s.FilePath)
 	if err != nil {
-		log.Panicf(fmt.Sprintf("Error to read file in path %v with error: %v", s.FilePath, err))
+		log.Panicf("Error to read file in path %v with error: %v", s.FilePath, err)
 	}
 	return strings.TrimSpace(string(apiKey))
 }
@@ -2,8 +2,11 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
+
+	"github.com/spf13/viper"
 )
 
 type AppConfig struct {
@@ -13,6 +16,8 @@ type AppConfig struct {
 	DB          DB       `mapstructure:"db"`
 	Services    Services `yaml:"services"`
 	Core        Core     `yaml:"core"`
+	Pool        Pool     `mapstructure:"pool"`
+	Wait        Wait     `mapstructure:"wait"`
 }
 
 type Auth struct {
@@ -57,6 +62,85 @@ type Postgres struct {
 	TraceStacks        bool          `yaml:"trace_stacks" mapstructure:"trace_stacks"`
 }
 
+// Pool sizes the worker pool, every field can be overridden by the environment, e.g. POOL_WORKERS=20.
+// The pool starts Workers workers, grows up to MaxWorkers while tasks wait and shrinks down to MinWorkers,
+// one worker for every IdleTimeout it stays idle. The bounds hold the count an admin may set as well, zero
+// bounds keep the pool at Workers.
+// DefaultTimeout bounds the run of the tasks which do not set their own timeout, zero means no limit.
+// ShutdownGrace is the time the in-flight work gets to finish once the service is asked to stop. DrainDelay
+// is the time the service keeps serving after it starts reporting not ready, before the grace starts, so the
+// load balancers stop routing to it first. Zero stops the server at once.
+// SubmitPolicy is one of reject, block or backlog and decides what happens to a new task while the queue
+// is full, a blocked submission waits at most SubmitTimeout.
+type Pool struct {
+	Workers        int           `yaml:"workers" mapstructure:"workers"`
+	MinWorkers     int           `yaml:"min_workers" mapstructure:"min_workers"`
+	MaxWorkers     int           `yaml:"max_workers" mapstructure:"max_workers"`
+	IdleTimeout    time.Duration `yaml:"idle_timeout" mapstructure:"idle_timeout"`
+	QueueSize      int           `yaml:"queue_size" mapstructure:"queue_size"`
+	ShutdownGrace  time.Duration `yaml:"shutdown_grace" mapstructure:"shutdown_grace"`
+	DrainDelay     time.Duration `yaml:"drain_delay" mapstructure:"drain_delay"`
+	DefaultTimeout time.Duration `yaml:"default_timeout" mapstructure:"default_timeout"`
+	SubmitPolicy   string        `yaml:"submit_policy" mapstructure:"submit_policy"`
+	SubmitTimeout  time.Duration `yaml:"submit_timeout" mapstructure:"submit_timeout"`
+}
+
+// Validate reports the pool settings the pool can not run with, a zero scaling bound or duration keeps the
+// default of the pool
+func (p Pool) Validate() error {
+	var errs []error
+	if p.Workers <= 0 {
+		errs = append(errs, fmt.Errorf("pool.workers must be positive, got %d", p.Workers))
+	}
+
+	if p.QueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("pool.queue_size must be positive, got %d", p.QueueSize))
+	}
+
+	if p.MinWorkers < 0 || p.MinWorkers > p.Workers {
+		errs = append(errs, fmt.Errorf("pool.min_workers must be between 0 and pool.workers (%d), got %d", p.Workers, p.MinWorkers))
+	}
+
+	if p.MaxWorkers != 0 && p.MaxWorkers < p.Workers {
+		errs = append(errs, fmt.Errorf("pool.max_workers must be 0 or at least pool.workers (%d), got %d", p.Workers, p.MaxWorkers))
+	}
+
+	if p.MaxWorkers != 0 && p.MinWorkers > p.MaxWorkers {
+		errs = append(errs, fmt.Errorf("pool.min_workers (%d) must not exceed pool.max_workers (%d)", p.MinWorkers, p.MaxWorkers))
+	}
+
+	switch p.SubmitPolicy {
+	case "reject", "block", "backlog":
+	default:
+		errs = append(errs, fmt.Errorf("pool.submit_policy must be one of reject, block or backlog, got %q", p.SubmitPolicy))
+	}
+
+	durations := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"pool.idle_timeout", p.IdleTimeout},
+		{"pool.shutdown_grace", p.ShutdownGrace},
+		{"pool.drain_delay", p.DrainDelay},
+		{"pool.default_timeout", p.DefaultTimeout},
+		{"pool.submit_timeout", p.SubmitTimeout},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", d.key, d.value))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Wait tunes the waits for a task, they end on the status changes this executor records. PollInterval, when
+// set, reads the waited tasks every interval so the tasks settled by other executors end their waits as well,
+// zero turns the reads off.
+type Wait struct {
+	PollInterval time.Duration `yaml:"poll_interval" mapstructure:"poll_interval"`
+}
+
 type Redis struct {
 	Address  string `yaml:"address"`
 	Password string `mask:"filled" yaml:"password"`
@@ -66,6 +150,7 @@ type Redis struct {
 type Services struct{}
 
 func LoadConfig(configPath string) *AppConfig {
+	setDefaults()
 	conf := NewConfig(configPath, &AppConfig{})
 	configJson, err := json.Marshal(conf.Internal.(*AppConfig))
 	if err != nil {
@@ -75,3 +160,19 @@ func LoadConfig(configPath string) *AppConfig {
 
 	return conf.Internal.(*AppConfig)
 }
+
+// setDefaults registers the values used when neither the config file nor the environment sets them,
+// a key has to be known to viper before it can be read from the environment
+func setDefaults() {
+	viper.SetDefault("pool.workers", 10)
+	viper.SetDefault("pool.min_workers", 0)
+	viper.SetDefault("pool.max_workers", 0)
+	viper.SetDefault("pool.idle_timeout", 30*time.Second)
+	viper.SetDefault("pool.queue_size", 10)
+	viper.SetDefault("pool.shutdown_grace", 30*time.Second)
+	viper.SetDefault("pool.drain_delay", time.Duration(0))
+	viper.SetDefault("pool.default_timeout", 5*time.Minute)
+	viper.SetDefault("pool.submit_policy", "backlog")
+	viper.SetDefault("pool.submit_timeout", 5*time.Second)
+	viper.SetDefault("wait.poll_interval", time.Duration(0))
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadPool reads the pool section the way LoadConfig does, from the defaults and the given config file, and
+// validates it
+func loadPool(t *testing.T, content string) (Pool, error) {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	setDefaults()
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	conf := Config{configPath: path, Internal: &AppConfig{}}
+	section, err := conf.loadConf()
+	if err != nil {
+		return Pool{}, err
+	}
+
+	pool := section.(*AppConfig).Pool
+	return pool, pool.Validate()
+}
+
+func TestPool_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "defaults",
+			content: "pool: {}",
+		},
+		{
+			name:    "scaling bounds around the workers",
+			content: "pool: {workers: 4, min_workers: 2, max_workers: 8}",
+		},
+		{
+			name:    "min workers above max workers",
+			content: "pool: {workers: 4, min_workers: 6, max_workers: 5}",
+			wantErr: "pool.min_workers (6) must not exceed pool.max_workers (5)",
+		},
+		{
+			name:    "workers below min workers",
+			content: "pool: {workers: 2, min_workers: 3, max_workers: 8}",
+			wantErr: "pool.min_workers must be between 0 and pool.workers (2), got 3",
+		},
+		{
+			name:    "workers above max workers",
+			content: "pool: {workers: 10, min_workers: 2, max_workers: 8}",
+			wantErr: "pool.max_workers must be 0 or at least pool.workers (10), got 8",
+		},
+		{
+			name:    "no workers",
+			content: "pool: {workers: 0}",
+			wantErr: "pool.workers must be positive, got 0",
+		},
+		{
+			name:    "empty queue",
+			content: "pool: {queue_size: 0}",
+			wantErr: "pool.queue_size must be positive, got 0",
+		},
+		{
+			name:    "negative queue",
+			content: "pool: {queue_size: -1}",
+			wantErr: "pool.queue_size must be positive, got -1",
+		},
+		{
+			name:    "unknown submit policy",
+			content: "pool: {submit_policy: drop}",
+			wantErr: `pool.submit_policy must be one of reject, block or backlog, got "drop"`,
+		},
+		{
+			name:    "negative duration",
+			content: "pool: {shutdown_grace: -1s}",
+			wantErr: "pool.shutdown_grace must not be negative, got -1s",
+		},
+		{
+			name:    "idle timeout which does not parse",
+			content: "pool: {idle_timeout: soon}",
+			wantErr: "'pool.idle_timeout' time: invalid duration",
+		},
+		{
+			name:    "submit timeout without a unit",
+			content: "pool: {submit_timeout: 5 seconds}",
+			wantErr: "'pool.submit_timeout' time: unknown unit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadPool(t, tt.content)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync"
 
 	"gorm.io/gorm"
 )
@@ -22,6 +23,14 @@ const (
 
 type txKey struct{}
 
+type afterCommitKey struct{}
+
+// afterCommit holds the functions to run once the transaction of a context commits
+type afterCommit struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
 type DB interface {
 	Create(value interface{}) (tx *gorm.DB)
 	CreateInBatches(value interface{}, batchSize int) (tx *gorm.DB)
@@ -52,6 +61,7 @@ type DB interface {
 	SavePoint(name string) *gorm.DB
 	RollbackTo(name string) *gorm.DB
 	Exec(sql string, values ...interface{}) (tx *gorm.DB)
+	Raw(sql string, values ...interface{}) (tx *gorm.DB)
 	WithContext(ctx context.Context) *gorm.DB
 	Model(value interface{}) (tx *gorm.DB)
 	Table(name string, args ...interface{}) (tx *gorm.DB)
@@ -81,10 +91,11 @@ func BeginTx(ctx context.Context, db DB) (*gorm.DB, context.Context, error) {
 	if !ok {
 		return nil, ctx, ErrDBType
 	}
-	ctx, _ = context.WithTimeout(ctx, transactionTimeOut)
+	ctx, cancel := context.WithTimeout(ctx, transactionTimeOut)
 
 	tx = dbt.Begin().WithContext(ctx)
 	go func() {
+		defer cancel()
 		<-ctx.Done()
 		tx.Rollback()
 		fmt.Println("The context has been canceled and transaction timeout")
@@ -94,5 +105,43 @@ func BeginTx(ctx context.Context, db DB) (*gorm.DB, context.Context, error) {
 }
 
 func withTx(ctx context.Context, tx *gorm.DB) context.Context {
+	ctx = context.WithValue(ctx, afterCommitKey{}, &afterCommit{})
 	return context.WithValue(ctx, txKey{}, tx)
 }
+
+// AfterCommit runs fn once the transaction of ctx is committed with Commit, it is dropped when the transaction
+// rolls back. fn runs right away when ctx holds no transaction.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommit)
+	if !ok {
+		fn()
+		return
+	}
+
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.fns = append(hooks.fns, fn)
+}
+
+// Commit commits the transaction started by BeginTx and then runs the functions registered with AfterCommit
+func Commit(ctx context.Context, tx *gorm.DB) error {
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommit)
+	if !ok {
+		return nil
+	}
+
+	hooks.mu.Lock()
+	fns := hooks.fns
+	hooks.fns = nil
+	hooks.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +26,42 @@ func NewDBWrapper(db *gorm.DB) DBWrapper {
 	}
 }
 
+// Close closes the connection pool under the gorm handle
+func (db DBWrapper) Close() error {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
+
+// Transactor runs fn in a single transaction, the context handed to fn carries it
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// InTx starts a transaction with BeginTx, commits it with Commit once fn succeeds and rolls it back when fn fails
+func (db DBWrapper) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// The transaction is rolled back when the context ends, it ends with InTx instead of the timeout
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tx, ctx, err := BeginTx(ctx, db.DB)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(ctx); err != nil {
+		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	return Commit(ctx, tx)
+}
+
 type Entity interface {
 	GetDomain() string
 }
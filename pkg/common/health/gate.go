@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Gate is a readiness check which fails with the reason it is closed until it is opened, it follows
+// the phases of a process such as startup and shutdown
+type Gate struct {
+	reason string
+
+	mu sync.RWMutex
+}
+
+func NewGate(reason string) *Gate {
+	return &Gate{reason: reason}
+}
+
+func (g *Gate) Open() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.reason = ""
+}
+
+func (g *Gate) Close(reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.reason = reason
+}
+
+func (g *Gate) Check(ctx context.Context) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.reason != "" {
+		return errors.New(g.reason)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "UP"
+	StatusDown Status = "DOWN"
+)
+
+// defaultCheckTimeout bounds a single check, a check which does not answer in time is down
+const defaultCheckTimeout = 2 * time.Second
+
+// Check returns why the component is unhealthy, or nil when it is healthy
+type Check func(ctx context.Context) error
+
+type ComponentReport struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     Status                     `json:"status"`
+	Components map[string]ComponentReport `json:"components"`
+}
+
+// Registry holds the liveness and readiness checks of the subsystems, a subsystem plugs in by adding its
+// checks under its own name
+type Registry struct {
+	liveness  map[string]Check
+	readiness map[string]Check
+	timeout   time.Duration
+
+	mu sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		liveness:  make(map[string]Check),
+		readiness: make(map[string]Check),
+		timeout:   defaultCheckTimeout,
+	}
+}
+
+// AddLiveness adds a check which tells whether the component can make progress at all, a failing
+// liveness check asks for a restart
+func (r *Registry) AddLiveness(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness[name] = check
+}
+
+// AddReadiness adds a check which tells whether the component can serve requests right now
+func (r *Registry) AddReadiness(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness[name] = check
+}
+
+func (r *Registry) Live(ctx context.Context) Report {
+	return r.run(ctx, r.checks(r.liveness))
+}
+
+func (r *Registry) Ready(ctx context.Context) Report {
+	return r.run(ctx, r.checks(r.readiness))
+}
+
+func (r *Registry) checks(from map[string]Check) map[string]Check {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	checks := make(map[string]Check, len(from))
+	for name, check := range from {
+		checks[name] = check
+	}
+
+	return checks
+}
+
+// run runs the checks concurrently, the report is up only when every check is
+func (r *Registry) run(ctx context.Context, checks map[string]Check) Report {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	results := make(chan result, len(checks))
+	for name, check := range checks {
+		go func() {
+			results <- result{name: name, err: check(ctx)}
+		}()
+	}
+
+	report := Report{Status: StatusUp, Components: make(map[string]ComponentReport, len(checks))}
+	for range checks {
+		var res result
+		select {
+		case res = <-results:
+		case <-ctx.Done():
+			return timedOut(report, checks, ctx.Err())
+		}
+
+		if res.err != nil {
+			report.Status = StatusDown
+			report.Components[res.name] = ComponentReport{Status: StatusDown, Error: res.err.Error()}
+			continue
+		}
+		report.Components[res.name] = ComponentReport{Status: StatusUp}
+	}
+
+	return report
+}
+
+// timedOut marks the checks which did not answer in time as down
+func timedOut(report Report, checks map[string]Check, err error) Report {
+	report.Status = StatusDown
+	for name := range checks {
+		if _, ok := report.Components[name]; !ok {
+			report.Components[name] = ComponentReport{Status: StatusDown, Error: err.Error()}
+		}
+	}
+
+	return report
+}
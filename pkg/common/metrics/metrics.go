@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// RegisterDBStats exports the sql.DBStats of the connection pool as gauges labelled with the database name
+func RegisterDBStats(reg prometheus.Registerer, name string, db *sql.DB) error {
+	return reg.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics of the default registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
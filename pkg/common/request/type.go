@@ -16,7 +16,7 @@ const (
 
 // swagger:model SortSpec
 type SortSpec struct {
-	SortType *SortType `json:"sortType" form:"sortType" default:"DESC" required:"false" enums:"ASC,DESC"`
+	SortType *SortType `json:"sortType" form:"sortType" default:"DESC" required:"false" enums:"ASC,DESC" validate:"omitempty,oneof=ASC DESC"`
 }
 
 // Pagination used to RAW DTO, in transport layer. it will be transformed to `Portion` for
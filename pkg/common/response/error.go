@@ -2,7 +2,9 @@ package response
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,7 +28,11 @@ func HandelError(ctx *gin.Context, err error) {
 	cause := causeErr.Cause
 
 	if err.(*Error).Cause == nil {
-		errors.As(err, &cause)
+		cause = err
+	}
+
+	if retryAfter := err.(*Error).RetryAfter; retryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
 	}
 
 	switch {
@@ -42,6 +48,10 @@ func HandelError(ctx *gin.Context, err error) {
 		status = http.StatusUnprocessableEntity
 	case IsUnauthorized(err):
 		status = http.StatusUnauthorized
+	case IsTooManyRequests(err):
+		status = http.StatusTooManyRequests
+	case IsUnavailable(err):
+		status = http.StatusServiceUnavailable
 	default:
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, BaseResponse{
 			Payload: nil,
@@ -3,6 +3,7 @@ package response
 import (
 	"errors"
 	"strings"
+	"time"
 )
 
 // ErrClass is the response class.
@@ -10,31 +11,35 @@ type ErrClass int
 
 // List of response classes.
 const (
-	EUnknown      ErrClass = iota // Unknown response
-	EFile                         // File response
-	EDB                           // Database response
-	ENetwork                      // Network response
-	EBadArg                       // Bad argument
-	EAccess                       // Access denied
-	ENotFound                     // Not found
-	ETimeout                      // Operation timed out
-	EConflict                     // Conflict
-	EValidation                   // Validation
-	EUnauthorized                 // Validation,
+	EUnknown         ErrClass = iota // Unknown response
+	EFile                            // File response
+	EDB                              // Database response
+	ENetwork                         // Network response
+	EBadArg                          // Bad argument
+	EAccess                          // Access denied
+	ENotFound                        // Not found
+	ETimeout                         // Operation timed out
+	EConflict                        // Conflict
+	EValidation                      // Validation
+	EUnauthorized                    // Validation,
+	ETooManyRequests                 // Too many requests, the client should retry later
+	EUnavailable                     // Service unavailable
 )
 
 var errCLasses = map[ErrClass]string{
-	EUnknown:      "unknown",
-	EFile:         "file",
-	EDB:           "db",
-	ENetwork:      "network",
-	EBadArg:       "badarg",
-	EAccess:       "access",
-	ENotFound:     "notfound",
-	ETimeout:      "timeout",
-	EConflict:     "conflict",
-	EValidation:   "validation",
-	EUnauthorized: "unauthorized",
+	EUnknown:         "unknown",
+	EFile:            "file",
+	EDB:              "db",
+	ENetwork:         "network",
+	EBadArg:          "badarg",
+	EAccess:          "access",
+	ENotFound:        "notfound",
+	ETimeout:         "timeout",
+	EConflict:        "conflict",
+	EValidation:      "validation",
+	EUnauthorized:    "unauthorized",
+	ETooManyRequests: "toomanyrequests",
+	EUnavailable:     "unavailable",
 }
 
 // String returns the response class name.
@@ -47,12 +52,13 @@ func (e ErrClass) String() string {
 
 // Error is an response type that can be used to return errors from services.
 type Error struct {
-	Service string   `json:"service"` // Service name.
-	Message string   `json:"message"` // Error message.
-	Cause   error    `json:"cause"`   // Underlying response.
-	Class   ErrClass `json:"class"`   // Error class.
-	IsTemp  bool     `json:"isTemp"`  // Is the response temporary?
-	ErrCode int64    `json:"errCode"`
+	Service    string        `json:"service"` // Service name.
+	Message    string        `json:"message"` // Error message.
+	Cause      error         `json:"cause"`   // Underlying response.
+	Class      ErrClass      `json:"class"`   // Error class.
+	IsTemp     bool          `json:"isTemp"`  // Is the response temporary?
+	ErrCode    int64         `json:"errCode"`
+	RetryAfter time.Duration `json:"retryAfter"` // Sent as the Retry-After header when set.
 }
 
 // Error returns the full response message.
@@ -118,3 +124,17 @@ func IsUnauthorized(err error) bool {
 	ok := errors.As(err, &se)
 	return ok && se.Class == EUnauthorized
 }
+
+// IsTooManyRequests returns true if the response is a too many requests response.
+func IsTooManyRequests(err error) bool {
+	var se *Error
+	ok := errors.As(err, &se)
+	return ok && se.Class == ETooManyRequests
+}
+
+// IsUnavailable returns true if the response is a service unavailable response.
+func IsUnavailable(err error) bool {
+	var se *Error
+	ok := errors.As(err, &se)
+	return ok && se.Class == EUnavailable
+}
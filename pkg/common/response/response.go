@@ -15,6 +15,16 @@ func CreatedResponse(ctx *gin.Context, body any) {
 	})
 }
 
+// StatusResponse answers with the given status, it replays a response which was stored earlier
+func StatusResponse(ctx *gin.Context, status int, body any) {
+	ctx.JSON(status, BaseResponse{
+		Payload: body,
+		Meta: ErrResponse{
+			Causes: []any{},
+		},
+	})
+}
+
 func OKResponse(ctx *gin.Context, body any) {
 	ctx.JSON(http.StatusOK, BaseResponse{
 		Payload: body,
@@ -24,6 +34,15 @@ func OKResponse(ctx *gin.Context, body any) {
 	})
 }
 
+func AcceptedResponse(ctx *gin.Context, body any) {
+	ctx.JSON(http.StatusAccepted, BaseResponse{
+		Payload: body,
+		Meta: ErrResponse{
+			Causes: []any{},
+		},
+	})
+}
+
 func NoContentResponse(ctx *gin.Context) {
 	ctx.AbortWithStatus(http.StatusNoContent)
 }
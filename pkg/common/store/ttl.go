@@ -0,0 +1,76 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+type ttlItem[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTLStore keeps up to maxItems values in memory until they expire, the expired values are dropped when
+// they are read or when the store is full
+type TTLStore[V any] struct {
+	items    map[string]ttlItem[V]
+	maxItems int
+
+	mu sync.RWMutex
+}
+
+func NewTTLStore[V any](maxItems int) *TTLStore[V] {
+	return &TTLStore[V]{
+		items:    make(map[string]ttlItem[V]),
+		maxItems: maxItems,
+	}
+}
+
+// Set keeps the value until expiresAt, it reports false when the store is full of live values
+func (s *TTLStore[V]) Set(key string, value V, expiresAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[key]; !ok && len(s.items) >= s.maxItems {
+		s.purge(time.Now())
+		if len(s.items) >= s.maxItems {
+			return false
+		}
+	}
+
+	s.items[key] = ttlItem[V]{value: value, expiresAt: expiresAt}
+	return true
+}
+
+func (s *TTLStore[V]) Get(key string) (V, bool) {
+	s.mu.RLock()
+	item, ok := s.items[key]
+	s.mu.RUnlock()
+
+	if ok && time.Now().After(item.expiresAt) {
+		s.Delete(key)
+		ok = false
+	}
+
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	return item.value, true
+}
+
+func (s *TTLStore[V]) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+}
+
+// purge must be called while holding the lock
+func (s *TTLStore[V]) purge(now time.Time) {
+	for key, item := range s.items {
+		if now.After(item.expiresAt) {
+			delete(s.items, key)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -71,6 +72,17 @@ func Validate(ctx context.Context, in interface{}) error {
 		return err
 	}
 
+	if err := validate.RegisterValidation("duration", func(fl validator.FieldLevel) bool {
+		data, ok := fl.Field().Interface().(string)
+		if !ok {
+			return false
+		}
+		value, err := time.ParseDuration(data)
+		return err == nil && value >= 0
+	}); err != nil {
+		return err
+	}
+
 	err := validate.StructCtx(ctx, in)
 	if err != nil {
 		errList := validateStruct(err)